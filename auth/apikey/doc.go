@@ -0,0 +1,4 @@
+// Package apikey provides authentication with static API keys for servers and
+// clients. Transport request funcs move keys between requests and the context,
+// and an endpoint middleware validates them against a key store.
+package apikey
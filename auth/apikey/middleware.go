@@ -0,0 +1,110 @@
+package apikey
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/guherbozdogan/kit/endpoint"
+)
+
+type contextKey string
+
+const (
+	// KeyContextKey holds the key used to store the API key passed with a
+	// request in the context.
+	KeyContextKey contextKey = "APIKey"
+
+	// PrincipalContextKey holds the key used to store the principal the API
+	// key belongs to in the context.
+	PrincipalContextKey contextKey = "APIKeyPrincipal"
+)
+
+var (
+	// ErrKeyMissing denotes no API key was passed into the middleware's
+	// context.
+	ErrKeyMissing = errors.New("API key missing")
+
+	// ErrKeyInvalid denotes the API key isn't in the store.
+	ErrKeyInvalid = errors.New("API key invalid")
+)
+
+// AuthError is returned by the middleware for unauthenticated requests. Err is
+// ErrKeyMissing or ErrKeyInvalid. AuthError implements
+// transport/http.StatusCoder, yielding status 401, and carries the gRPC
+// Unauthenticated code.
+type AuthError struct {
+	Err error
+}
+
+// Error implements the error interface.
+func (e *AuthError) Error() string {
+	return e.Err.Error()
+}
+
+// StatusCode implements transport/http.StatusCoder.
+func (e *AuthError) StatusCode() int {
+	return http.StatusUnauthorized
+}
+
+// GRPCStatus returns the gRPC status of the error, which has the
+// Unauthenticated code.
+func (e *AuthError) GRPCStatus() *status.Status {
+	return status.New(codes.Unauthenticated, e.Error())
+}
+
+// Store validates API keys.
+type Store interface {
+	// Lookup returns the principal, e.g. the name of the client, that the key
+	// belongs to, and false if the key is invalid. Implementations should
+	// compare keys in constant time.
+	Lookup(key string) (principal string, ok bool)
+}
+
+// StaticStore is a Store of a fixed set of API keys, mapped to the principals
+// they belong to. Keys are compared in constant time.
+type StaticStore map[string]string
+
+// Lookup implements Store.
+func (s StaticStore) Lookup(key string) (string, bool) {
+	// Compare digests against every key, so that neither the map lookup nor the
+	// comparison leaks which keys exist.
+	var (
+		haveSum   = sha256.Sum256([]byte(key))
+		principal string
+		found     int
+	)
+	for k, p := range s {
+		wantSum := sha256.Sum256([]byte(k))
+		if subtle.ConstantTimeCompare(wantSum[:], haveSum[:]) == 1 {
+			principal, found = p, 1
+		}
+	}
+	return principal, found == 1
+}
+
+// NewAuthenticator returns a middleware which looks up the API key in the
+// context, as stored by ToHTTPContext or ToGRPCContext, in the store. Requests
+// with missing or invalid keys are rejected with an *AuthError. The principal
+// the key belongs to is added to the context via PrincipalContextKey.
+func NewAuthenticator(store Store) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			key, ok := ctx.Value(KeyContextKey).(string)
+			if !ok {
+				return nil, &AuthError{Err: ErrKeyMissing}
+			}
+			principal, ok := store.Lookup(key)
+			if !ok {
+				return nil, &AuthError{Err: ErrKeyInvalid}
+			}
+			ctx = context.WithValue(ctx, PrincipalContextKey, principal)
+			return next(ctx, request)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package apikey
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+func TestAuthenticator(t *testing.T) {
+	var (
+		store = StaticStore{"k1": "ci", "k2": "dashboard"}
+		e     = func(ctx context.Context, _ interface{}) (interface{}, error) { return ctx, nil }
+		auth  = NewAuthenticator(store)(e)
+	)
+
+	for _, tc := range []struct {
+		ctx  context.Context
+		want error
+	}{
+		{context.Background(), ErrKeyMissing},
+		{context.WithValue(context.Background(), KeyContextKey, "k3"), ErrKeyInvalid},
+		{context.WithValue(context.Background(), KeyContextKey, ""), ErrKeyInvalid},
+	} {
+		_, err := auth(tc.ctx, struct{}{})
+		authErr, ok := err.(*AuthError)
+		if !ok {
+			t.Fatalf("want *AuthError, have %T", err)
+		}
+		if want, have := tc.want, authErr.Err; want != have {
+			t.Errorf("want %v, have %v", want, have)
+		}
+		if want, have := http.StatusUnauthorized, authErr.StatusCode(); want != have {
+			t.Errorf("want %d, have %d", want, have)
+		}
+		if want, have := codes.Unauthenticated, grpc.Code(err); want != have {
+			t.Errorf("want %s, have %s", want, have)
+		}
+	}
+
+	ctx, err := auth(context.WithValue(context.Background(), KeyContextKey, "k2"), struct{}{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, have := "dashboard", ctx.(context.Context).Value(PrincipalContextKey); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+}
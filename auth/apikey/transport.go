@@ -0,0 +1,63 @@
+package apikey
+
+import (
+	"context"
+	stdhttp "net/http"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+
+	"github.com/guherbozdogan/kit/transport/grpc"
+	"github.com/guherbozdogan/kit/transport/http"
+)
+
+// DefaultHeader is the conventional header that carries API keys.
+const DefaultHeader = "X-API-Key"
+
+// ToHTTPContext moves the API key from the named request header to the
+// context. Particularly useful for servers.
+func ToHTTPContext(header string) http.RequestFunc {
+	return func(ctx context.Context, r *stdhttp.Request) context.Context {
+		key := r.Header.Get(header)
+		if key == "" {
+			return ctx
+		}
+		return context.WithValue(ctx, KeyContextKey, key)
+	}
+}
+
+// FromHTTPContext moves the API key from the context to the named request
+// header. Particularly useful for clients.
+func FromHTTPContext(header string) http.RequestFunc {
+	return func(ctx context.Context, r *stdhttp.Request) context.Context {
+		if key, ok := ctx.Value(KeyContextKey).(string); ok {
+			r.Header.Set(header, key)
+		}
+		return ctx
+	}
+}
+
+// ToGRPCContext moves the API key from the named grpc metadata key to the
+// context. Particularly useful for servers.
+func ToGRPCContext(header string) grpc.ServerRequestFunc {
+	return func(ctx context.Context, md metadata.MD) context.Context {
+		// capital "Key" is illegal in HTTP/2.
+		values, ok := md[strings.ToLower(header)]
+		if !ok || len(values) == 0 || values[0] == "" {
+			return ctx
+		}
+		return context.WithValue(ctx, KeyContextKey, values[0])
+	}
+}
+
+// FromGRPCContext moves the API key from the context to the named grpc
+// metadata key. Particularly useful for clients.
+func FromGRPCContext(header string) grpc.ClientRequestFunc {
+	return func(ctx context.Context, md *metadata.MD) context.Context {
+		if key, ok := ctx.Value(KeyContextKey).(string); ok {
+			// capital "Key" is illegal in HTTP/2.
+			(*md)[strings.ToLower(header)] = []string{key}
+		}
+		return ctx
+	}
+}
@@ -0,0 +1,38 @@
+package apikey
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"google.golang.org/grpc/metadata"
+)
+
+func TestHTTPContext(t *testing.T) {
+	ctx := ToHTTPContext(DefaultHeader)(context.Background(), &http.Request{Header: http.Header{}})
+	if ctx.Value(KeyContextKey) != nil {
+		t.Error("Context shouldn't contain a key")
+	}
+
+	r, _ := http.NewRequest("GET", "/", nil)
+	FromHTTPContext(DefaultHeader)(context.WithValue(context.Background(), KeyContextKey, "k1"), r)
+	if want, have := "k1", r.Header.Get("X-Api-Key"); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+	ctx = ToHTTPContext(DefaultHeader)(context.Background(), r)
+	if want, have := "k1", ctx.Value(KeyContextKey); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+}
+
+func TestGRPCContext(t *testing.T) {
+	md := metadata.MD{}
+	FromGRPCContext(DefaultHeader)(context.WithValue(context.Background(), KeyContextKey, "k1"), &md)
+	if want, have := "k1", md["x-api-key"][0]; want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+	ctx := ToGRPCContext(DefaultHeader)(context.Background(), md)
+	if want, have := "k1", ctx.Value(KeyContextKey); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+}
@@ -0,0 +1,5 @@
+// Package basic provides HTTP Basic authentication (RFC 7617) for servers and
+// clients. Transport request funcs move credentials between requests and the
+// context, and an endpoint middleware validates them against a credential
+// store.
+package basic
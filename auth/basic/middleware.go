@@ -0,0 +1,116 @@
+package basic
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/guherbozdogan/kit/endpoint"
+)
+
+type contextKey string
+
+const (
+	// CredentialsContextKey holds the key used to store the Credentials passed
+	// with a request in the context.
+	CredentialsContextKey contextKey = "BasicCredentials"
+
+	// UserContextKey holds the key used to store the name of the authenticated
+	// user in the context.
+	UserContextKey contextKey = "BasicUser"
+)
+
+// Credentials are a username and password.
+type Credentials struct {
+	Username string
+	Password string
+}
+
+var (
+	// ErrCredentialsMissing denotes no credentials were passed into the
+	// middleware's context.
+	ErrCredentialsMissing = errors.New("credentials missing")
+
+	// ErrCredentialsInvalid denotes the credentials didn't match the store.
+	ErrCredentialsInvalid = errors.New("credentials invalid")
+)
+
+// AuthError is returned by the middleware for unauthenticated requests. Err is
+// ErrCredentialsMissing or ErrCredentialsInvalid. AuthError implements
+// transport/http.StatusCoder and transport/http.Headerer, yielding status 401
+// with a Basic challenge, and carries the gRPC Unauthenticated code.
+type AuthError struct {
+	Realm string
+	Err   error
+}
+
+// Error implements the error interface.
+func (e *AuthError) Error() string {
+	return e.Err.Error()
+}
+
+// StatusCode implements transport/http.StatusCoder.
+func (e *AuthError) StatusCode() int {
+	return http.StatusUnauthorized
+}
+
+// Headers implements transport/http.Headerer.
+func (e *AuthError) Headers() http.Header {
+	return http.Header{"Www-Authenticate": []string{fmt.Sprintf("Basic realm=%q", e.Realm)}}
+}
+
+// GRPCStatus returns the gRPC status of the error, which has the
+// Unauthenticated code.
+func (e *AuthError) GRPCStatus() *status.Status {
+	return status.New(codes.Unauthenticated, e.Error())
+}
+
+// Store validates credentials.
+type Store interface {
+	// Authenticate returns true if the password is valid for the user.
+	// Implementations should compare secrets in constant time.
+	Authenticate(username, password string) bool
+}
+
+// StaticStore is a Store of a fixed set of users, mapped to their passwords.
+// Passwords are compared in constant time.
+type StaticStore map[string]string
+
+// Authenticate implements Store.
+func (s StaticStore) Authenticate(username, password string) bool {
+	want, ok := s[username]
+	// Compare digests, so that the comparison doesn't leak the length of the
+	// password, and compare even for unknown users.
+	var (
+		wantSum = sha256.Sum256([]byte(want))
+		haveSum = sha256.Sum256([]byte(password))
+	)
+	return subtle.ConstantTimeCompare(wantSum[:], haveSum[:]) == 1 && ok
+}
+
+// NewAuthenticator returns a middleware which authenticates the Credentials in
+// the context, as stored by ToHTTPContext or ToGRPCContext, against the store.
+// Requests with missing or invalid credentials are rejected with an
+// *AuthError for the realm. The name of the authenticated user is added to the
+// context via UserContextKey.
+func NewAuthenticator(store Store, realm string) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			creds, ok := ctx.Value(CredentialsContextKey).(Credentials)
+			if !ok {
+				return nil, &AuthError{Realm: realm, Err: ErrCredentialsMissing}
+			}
+			if !store.Authenticate(creds.Username, creds.Password) {
+				return nil, &AuthError{Realm: realm, Err: ErrCredentialsInvalid}
+			}
+			ctx = context.WithValue(ctx, UserContextKey, creds.Username)
+			return next(ctx, request)
+		}
+	}
+}
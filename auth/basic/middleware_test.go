@@ -0,0 +1,58 @@
+package basic
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+func TestAuthenticator(t *testing.T) {
+	var (
+		store = StaticStore{"alice": "secret"}
+		e     = func(ctx context.Context, _ interface{}) (interface{}, error) { return ctx, nil }
+		auth  = NewAuthenticator(store, "test")(e)
+	)
+
+	for _, tc := range []struct {
+		ctx  context.Context
+		want error
+	}{
+		{context.Background(), ErrCredentialsMissing},
+		{context.WithValue(context.Background(), CredentialsContextKey, Credentials{"alice", "wrong"}), ErrCredentialsInvalid},
+		{context.WithValue(context.Background(), CredentialsContextKey, Credentials{"bob", "secret"}), ErrCredentialsInvalid},
+		{context.WithValue(context.Background(), CredentialsContextKey, Credentials{"alice", ""}), ErrCredentialsInvalid},
+	} {
+		_, err := auth(tc.ctx, struct{}{})
+		authErr, ok := err.(*AuthError)
+		if !ok {
+			t.Fatalf("want *AuthError, have %T", err)
+		}
+		if want, have := tc.want, authErr.Err; want != have {
+			t.Errorf("want %v, have %v", want, have)
+		}
+	}
+
+	ctx, err := auth(context.WithValue(context.Background(), CredentialsContextKey, Credentials{"alice", "secret"}), struct{}{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, have := "alice", ctx.(context.Context).Value(UserContextKey); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+}
+
+func TestAuthError(t *testing.T) {
+	err := &AuthError{Realm: "test", Err: ErrCredentialsInvalid}
+	if want, have := http.StatusUnauthorized, err.StatusCode(); want != have {
+		t.Errorf("want %d, have %d", want, have)
+	}
+	if want, have := `Basic realm="test"`, err.Headers().Get("WWW-Authenticate"); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+	if want, have := codes.Unauthenticated, grpc.Code(err); want != have {
+		t.Errorf("want %s, have %s", want, have)
+	}
+}
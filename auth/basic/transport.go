@@ -0,0 +1,85 @@
+package basic
+
+import (
+	"context"
+	"encoding/base64"
+	stdhttp "net/http"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+
+	"github.com/guherbozdogan/kit/transport/grpc"
+	"github.com/guherbozdogan/kit/transport/http"
+)
+
+const basicPrefix = "basic "
+
+// ToHTTPContext moves Basic credentials from the request header to the
+// context. Particularly useful for servers.
+func ToHTTPContext() http.RequestFunc {
+	return func(ctx context.Context, r *stdhttp.Request) context.Context {
+		creds, ok := parseAuthHeader(r.Header.Get("Authorization"))
+		if !ok {
+			return ctx
+		}
+		return context.WithValue(ctx, CredentialsContextKey, creds)
+	}
+}
+
+// FromHTTPContext moves Basic credentials from the context to the request
+// header. Particularly useful for clients.
+func FromHTTPContext() http.RequestFunc {
+	return func(ctx context.Context, r *stdhttp.Request) context.Context {
+		if creds, ok := ctx.Value(CredentialsContextKey).(Credentials); ok {
+			r.SetBasicAuth(creds.Username, creds.Password)
+		}
+		return ctx
+	}
+}
+
+// ToGRPCContext moves Basic credentials from grpc metadata to the context.
+// Particularly useful for servers.
+func ToGRPCContext() grpc.ServerRequestFunc {
+	return func(ctx context.Context, md metadata.MD) context.Context {
+		// capital "Key" is illegal in HTTP/2.
+		authHeader, ok := md["authorization"]
+		if !ok || len(authHeader) == 0 {
+			return ctx
+		}
+		if creds, ok := parseAuthHeader(authHeader[0]); ok {
+			ctx = context.WithValue(ctx, CredentialsContextKey, creds)
+		}
+		return ctx
+	}
+}
+
+// FromGRPCContext moves Basic credentials from the context to grpc metadata.
+// Particularly useful for clients.
+func FromGRPCContext() grpc.ClientRequestFunc {
+	return func(ctx context.Context, md *metadata.MD) context.Context {
+		if creds, ok := ctx.Value(CredentialsContextKey).(Credentials); ok {
+			// capital "Key" is illegal in HTTP/2.
+			(*md)["authorization"] = []string{authHeader(creds)}
+		}
+		return ctx
+	}
+}
+
+func parseAuthHeader(val string) (Credentials, bool) {
+	if len(val) < len(basicPrefix) || strings.ToLower(val[:len(basicPrefix)]) != basicPrefix {
+		return Credentials{}, false
+	}
+	b, err := base64.StdEncoding.DecodeString(val[len(basicPrefix):])
+	if err != nil {
+		return Credentials{}, false
+	}
+	i := strings.IndexByte(string(b), ':')
+	if i < 0 {
+		return Credentials{}, false
+	}
+	return Credentials{Username: string(b[:i]), Password: string(b[i+1:])}, true
+}
+
+func authHeader(creds Credentials) string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(creds.Username+":"+creds.Password))
+}
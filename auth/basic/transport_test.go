@@ -0,0 +1,45 @@
+package basic
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"google.golang.org/grpc/metadata"
+)
+
+func TestHTTPContext(t *testing.T) {
+	creds := Credentials{Username: "alice", Password: "pass:word"}
+
+	// When the header doesn't exist
+	ctx := ToHTTPContext()(context.Background(), &http.Request{Header: http.Header{}})
+	if ctx.Value(CredentialsContextKey) != nil {
+		t.Error("Context shouldn't contain credentials")
+	}
+
+	// Round trip
+	r, _ := http.NewRequest("GET", "/", nil)
+	FromHTTPContext()(context.WithValue(context.Background(), CredentialsContextKey, creds), r)
+	ctx = ToHTTPContext()(context.Background(), r)
+	if want, have := creds, ctx.Value(CredentialsContextKey); want != have {
+		t.Errorf("want %v, have %v", want, have)
+	}
+
+	// Other schemes are ignored
+	r.Header.Set("Authorization", "Bearer token")
+	ctx = ToHTTPContext()(context.Background(), r)
+	if ctx.Value(CredentialsContextKey) != nil {
+		t.Error("Context shouldn't contain credentials")
+	}
+}
+
+func TestGRPCContext(t *testing.T) {
+	creds := Credentials{Username: "alice", Password: "secret"}
+
+	md := metadata.MD{}
+	FromGRPCContext()(context.WithValue(context.Background(), CredentialsContextKey, creds), &md)
+	ctx := ToGRPCContext()(context.Background(), md)
+	if want, have := creds, ctx.Value(CredentialsContextKey); want != have {
+		t.Errorf("want %v, have %v", want, have)
+	}
+}
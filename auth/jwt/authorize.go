@@ -0,0 +1,180 @@
+package jwt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/guherbozdogan/kit/endpoint"
+)
+
+// AuthorizationError denotes the claims of a request violate an authorization
+// rule. It implements transport/http.StatusCoder with status 403, and carries
+// the gRPC PermissionDenied code, so that both transports report it properly.
+type AuthorizationError struct {
+	Rule   string // e.g. "scope"
+	Reason string
+}
+
+// Error implements the error interface.
+func (e *AuthorizationError) Error() string {
+	return fmt.Sprintf("permission denied: %s: %s", e.Rule, e.Reason)
+}
+
+// StatusCode implements transport/http.StatusCoder.
+func (e *AuthorizationError) StatusCode() int {
+	return http.StatusForbidden
+}
+
+// GRPCStatus returns the gRPC status of the error, which has the
+// PermissionDenied code.
+func (e *AuthorizationError) GRPCStatus() *status.Status {
+	return status.New(codes.PermissionDenied, e.Error())
+}
+
+// Rule authorizes a request based on the claims of its token. It returns nil
+// if the request is authorized, and preferably an *AuthorizationError
+// otherwise. Claims of any type are passed in their JSON form, so rules work
+// with standard and custom claims types alike.
+type Rule func(claims jwt.MapClaims) error
+
+// NewAuthorizer returns an authorization middleware, which must be placed
+// after (i.e. wrapped by) NewParser. It rejects requests whose claims violate
+// any of the rules with the rule's error.
+func NewAuthorizer(rules ...Rule) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+			claims, ok := ctx.Value(JWTClaimsContextKey).(jwt.Claims)
+			if !ok {
+				return nil, &AuthorizationError{Rule: "claims", Reason: "no claims in context"}
+			}
+			m, err := toMapClaims(claims)
+			if err != nil {
+				return nil, err
+			}
+			for _, rule := range rules {
+				if err := rule(m); err != nil {
+					return nil, err
+				}
+			}
+			return next(ctx, request)
+		}
+	}
+}
+
+// RequireScopes requires all of the scopes to be granted. Scopes are read from
+// the space-delimited "scope" claim (RFC 8693), or the "scp" claim, which may
+// also be a list.
+func RequireScopes(scopes ...string) Rule {
+	return func(claims jwt.MapClaims) error {
+		granted := append(claimStrings(claims["scope"], true), claimStrings(claims["scp"], true)...)
+		for _, scope := range scopes {
+			if !contains(granted, scope) {
+				return &AuthorizationError{Rule: "scope", Reason: fmt.Sprintf("scope %q not granted", scope)}
+			}
+		}
+		return nil
+	}
+}
+
+// RequireRoles requires the subject to have all of the roles. Roles are read
+// from the "roles" claim, which may be a list or a single string.
+func RequireRoles(roles ...string) Rule {
+	return func(claims jwt.MapClaims) error {
+		have := claimStrings(claims["roles"], false)
+		for _, role := range roles {
+			if !contains(have, role) {
+				return &AuthorizationError{Rule: "role", Reason: fmt.Sprintf("missing role %q", role)}
+			}
+		}
+		return nil
+	}
+}
+
+// RequireAudience requires the token to be intended for the audience. The
+// "aud" claim may be a list or a single string.
+func RequireAudience(aud string) Rule {
+	return func(claims jwt.MapClaims) error {
+		if !contains(claimStrings(claims["aud"], false), aud) {
+			return &AuthorizationError{Rule: "aud", Reason: fmt.Sprintf("not intended for %q", aud)}
+		}
+		return nil
+	}
+}
+
+// RequireIssuer requires the token to be issued by the issuer.
+func RequireIssuer(iss string) Rule {
+	return func(claims jwt.MapClaims) error {
+		if have, _ := claims["iss"].(string); have != iss {
+			return &AuthorizationError{Rule: "iss", Reason: fmt.Sprintf("not issued by %q", iss)}
+		}
+		return nil
+	}
+}
+
+// RequireClaim requires the predicate to hold for the named claim. The value
+// passed to the predicate is nil if the claim is absent, and otherwise has the
+// type encoding/json decodes it to, e.g. float64 for numbers.
+func RequireClaim(name string, predicate func(value interface{}) bool) Rule {
+	return func(claims jwt.MapClaims) error {
+		if !predicate(claims[name]) {
+			return &AuthorizationError{Rule: name, Reason: fmt.Sprintf("claim %q rejected", name)}
+		}
+		return nil
+	}
+}
+
+// toMapClaims returns claims in their JSON form.
+func toMapClaims(claims jwt.Claims) (jwt.MapClaims, error) {
+	if m, ok := claims.(jwt.MapClaims); ok {
+		return m, nil
+	}
+	b, err := json.Marshal(claims)
+	if err != nil {
+		return nil, err
+	}
+	var m jwt.MapClaims
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// claimStrings returns the strings in a list claim. A single string is a list
+// of one element, or of its space-delimited fields if split is true.
+func claimStrings(v interface{}, split bool) []string {
+	switch v := v.(type) {
+	case string:
+		if split {
+			return strings.Fields(v)
+		}
+		return []string{v}
+	case []string:
+		return v
+	case []interface{}:
+		s := make([]string, 0, len(v))
+		for _, e := range v {
+			if e, ok := e.(string); ok {
+				s = append(s, e)
+			}
+		}
+		return s
+	default:
+		return nil
+	}
+}
+
+func contains(s []string, e string) bool {
+	for _, x := range s {
+		if x == e {
+			return true
+		}
+	}
+	return false
+}
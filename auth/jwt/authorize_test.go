@@ -0,0 +1,80 @@
+package jwt
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+func TestAuthorizer(t *testing.T) {
+	e := func(context.Context, interface{}) (interface{}, error) { return struct{}{}, nil }
+	claims := jwt.MapClaims{
+		"iss":   "issuer",
+		"aud":   []interface{}{"a", "b"},
+		"scope": "read write",
+		"roles": []interface{}{"admin"},
+		"tier":  "gold",
+	}
+
+	for _, tc := range []struct {
+		name  string
+		rules []Rule
+		pass  bool
+	}{
+		{"no rules", nil, true},
+		{"scopes", []Rule{RequireScopes("read", "write")}, true},
+		{"missing scope", []Rule{RequireScopes("read", "delete")}, false},
+		{"roles", []Rule{RequireRoles("admin")}, true},
+		{"missing role", []Rule{RequireRoles("root")}, false},
+		{"audience", []Rule{RequireAudience("b")}, true},
+		{"wrong audience", []Rule{RequireAudience("c")}, false},
+		{"issuer", []Rule{RequireIssuer("issuer")}, true},
+		{"wrong issuer", []Rule{RequireIssuer("other")}, false},
+		{"claim", []Rule{RequireClaim("tier", func(v interface{}) bool { return v == "gold" })}, true},
+		{"rejected claim", []Rule{RequireClaim("tier", func(v interface{}) bool { return v == "silver" })}, false},
+		{"all", []Rule{RequireScopes("read"), RequireRoles("admin"), RequireIssuer("other")}, false},
+	} {
+		ctx := context.WithValue(context.Background(), JWTClaimsContextKey, claims)
+		_, err := NewAuthorizer(tc.rules...)(e)(ctx, struct{}{})
+		if tc.pass && err != nil {
+			t.Errorf("%s: want nil, have %v", tc.name, err)
+		}
+		if !tc.pass && err == nil {
+			t.Errorf("%s: want error, have nil", tc.name)
+		}
+	}
+}
+
+func TestAuthorizerStandardClaims(t *testing.T) {
+	e := func(context.Context, interface{}) (interface{}, error) { return struct{}{}, nil }
+	ctx := context.WithValue(context.Background(), JWTClaimsContextKey, myCustomClaims)
+
+	if _, err := NewAuthorizer(RequireAudience("go-kit"))(e)(ctx, struct{}{}); err != nil {
+		t.Error(err)
+	}
+	if _, err := NewAuthorizer(RequireClaim("my_property", func(v interface{}) bool { return v == myProperty }))(e)(ctx, struct{}{}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAuthorizationError(t *testing.T) {
+	e := func(context.Context, interface{}) (interface{}, error) { return struct{}{}, nil }
+	_, err := NewAuthorizer(RequireScopes("read"))(e)(context.Background(), struct{}{})
+
+	sc, ok := err.(interface {
+		StatusCode() int
+	})
+	if !ok {
+		t.Fatalf("want StatusCoder, have %T", err)
+	}
+	if want, have := http.StatusForbidden, sc.StatusCode(); want != have {
+		t.Errorf("want %d, have %d", want, have)
+	}
+	if want, have := codes.PermissionDenied, grpc.Code(err); want != have {
+		t.Errorf("want %s, have %s", want, have)
+	}
+}
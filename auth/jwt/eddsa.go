@@ -0,0 +1,55 @@
+package jwt
+
+import (
+	"errors"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"golang.org/x/crypto/ed25519"
+)
+
+// ErrEdDSAVerification denotes an EdDSA signature didn't match.
+var ErrEdDSAVerification = errors.New("EdDSA verification failed")
+
+// SigningMethodEdDSA implements the EdDSA signing method (RFC 8037) with
+// Ed25519 keys, which jwt-go doesn't provide. Sign expects an
+// ed25519.PrivateKey; Verify expects an ed25519.PublicKey.
+//
+// Importing this package registers SigningMethodEdDSA in jwt-go's global
+// registry under the "EdDSA" algorithm name, so that jwt-go parses tokens
+// using it, also outside of this package. It replaces any EdDSA method
+// registered before; register your own after importing this package to
+// override it.
+var SigningMethodEdDSA jwt.SigningMethod = signingMethodEdDSA{}
+
+func init() {
+	jwt.RegisterSigningMethod(SigningMethodEdDSA.Alg(), func() jwt.SigningMethod {
+		return SigningMethodEdDSA
+	})
+}
+
+type signingMethodEdDSA struct{}
+
+func (signingMethodEdDSA) Alg() string { return "EdDSA" }
+
+func (signingMethodEdDSA) Verify(signingString, signature string, key interface{}) error {
+	pub, ok := key.(ed25519.PublicKey)
+	if !ok || len(pub) != ed25519.PublicKeySize {
+		return jwt.ErrInvalidKeyType
+	}
+	sig, err := jwt.DecodeSegment(signature)
+	if err != nil {
+		return err
+	}
+	if !ed25519.Verify(pub, []byte(signingString), sig) {
+		return ErrEdDSAVerification
+	}
+	return nil
+}
+
+func (signingMethodEdDSA) Sign(signingString string, key interface{}) (string, error) {
+	priv, ok := key.(ed25519.PrivateKey)
+	if !ok || len(priv) != ed25519.PrivateKeySize {
+		return "", jwt.ErrInvalidKeyType
+	}
+	return jwt.EncodeSegment(ed25519.Sign(priv, []byte(signingString))), nil
+}
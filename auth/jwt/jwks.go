@@ -0,0 +1,275 @@
+package jwt
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"golang.org/x/crypto/ed25519"
+)
+
+var (
+	// ErrKIDMissing denotes a token has no key ID header (kid), so its key
+	// can't be looked up in a key set.
+	ErrKIDMissing = errors.New("token has no key ID")
+
+	// ErrUnknownKID denotes a token's key ID header (kid) doesn't refer to a
+	// key in the key set.
+	ErrUnknownKID = errors.New("unknown key ID")
+
+	// ErrUnsupportedKey denotes a JSON Web Key of an unsupported type or curve.
+	ErrUnsupportedKey = errors.New("unsupported JSON Web Key")
+)
+
+// DefaultJWKSRefreshInterval is the default minimum interval between two
+// fetches of a JWKS triggered by unknown key IDs.
+const DefaultJWKSRefreshInterval = time.Minute
+
+// JWKS resolves token keys from a JSON Web Key Set (RFC 7517) published at a
+// URL, as most identity providers do. Keys are cached by key ID. The set is
+// fetched when a token refers to a key ID that's not in the cache, at most once
+// per refresh interval, so that rotated keys are picked up without letting
+// tokens with bogus key IDs flood the provider. RSA, EC (P-256, P-384 and
+// P-521) and OKP (Ed25519) signing keys are supported; other keys are ignored.
+type JWKS struct {
+	url      string
+	client   *http.Client
+	interval time.Duration
+
+	mtx  sync.RWMutex
+	keys map[string]jsonWebKey
+
+	fetchmtx sync.Mutex
+	fetched  time.Time
+}
+
+// JWKSOption sets an optional parameter for JWKS.
+type JWKSOption func(*JWKS)
+
+// JWKSClient sets the HTTP client used to fetch the key set. By default, a
+// client with a 10 second timeout is used.
+func JWKSClient(client *http.Client) JWKSOption {
+	return func(k *JWKS) { k.client = client }
+}
+
+// JWKSRefreshInterval sets the minimum interval between two fetches of the
+// key set triggered by unknown key IDs. By default, DefaultJWKSRefreshInterval
+// is used.
+func JWKSRefreshInterval(d time.Duration) JWKSOption {
+	return func(k *JWKS) { k.interval = d }
+}
+
+// NewJWKS returns a JWKS fetching the key set at the given URL. The set is
+// fetched lazily, when the first token is parsed; call Refresh to fetch it
+// up front.
+func NewJWKS(url string, options ...JWKSOption) *JWKS {
+	k := &JWKS{
+		url:      url,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		interval: DefaultJWKSRefreshInterval,
+		keys:     map[string]jsonWebKey{},
+	}
+	for _, option := range options {
+		option(k)
+	}
+	return k
+}
+
+// Keyfunc implements jwt.Keyfunc, and can be passed to NewParser. It returns
+// the key referred to by the token's key ID header, fetching the key set if the
+// key isn't known yet. If the key specifies an algorithm, it must match the
+// token's signing method.
+func (k *JWKS) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, ok := token.Header["kid"].(string)
+	if !ok || kid == "" {
+		return nil, ErrKIDMissing
+	}
+
+	key, ok := k.lookup(kid)
+	if !ok {
+		var err error
+		if key, ok, err = k.refreshFor(kid); err != nil {
+			return nil, err
+		} else if !ok {
+			return nil, ErrUnknownKID
+		}
+	}
+
+	if key.alg != "" && key.alg != token.Method.Alg() {
+		return nil, ErrUnexpectedSigningMethod
+	}
+	return key.key, nil
+}
+
+// Refresh fetches the key set, and replaces the cached keys with it.
+func (k *JWKS) Refresh(ctx context.Context) error {
+	k.fetchmtx.Lock()
+	defer k.fetchmtx.Unlock()
+	return k.fetch(ctx)
+}
+
+func (k *JWKS) lookup(kid string) (jsonWebKey, bool) {
+	k.mtx.RLock()
+	defer k.mtx.RUnlock()
+	key, ok := k.keys[kid]
+	return key, ok
+}
+
+// refreshFor fetches the key set on behalf of a token with an unknown key ID,
+// unless it was fetched within the refresh interval.
+func (k *JWKS) refreshFor(kid string) (jsonWebKey, bool, error) {
+	k.fetchmtx.Lock()
+	defer k.fetchmtx.Unlock()
+
+	// Another token may have triggered a fetch while we were waiting.
+	if key, ok := k.lookup(kid); ok {
+		return key, true, nil
+	}
+	if !k.fetched.IsZero() && time.Since(k.fetched) < k.interval {
+		return jsonWebKey{}, false, nil
+	}
+	if err := k.fetch(context.Background()); err != nil {
+		return jsonWebKey{}, false, err
+	}
+	key, ok := k.lookup(kid)
+	return key, ok, nil
+}
+
+// fetch must be called with fetchmtx held.
+func (k *JWKS) fetch(ctx context.Context) error {
+	k.fetched = time.Now() // failed fetches count against the interval, too
+
+	req, err := http.NewRequest("GET", k.url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := k.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("fetching JWKS: %s", resp.Status)
+	}
+
+	var set struct {
+		Keys []jsonWebKeyJSON `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return fmt.Errorf("decoding JWKS: %v", err)
+	}
+
+	keys := make(map[string]jsonWebKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Kid == "" || (jwk.Use != "" && jwk.Use != "sig") {
+			continue
+		}
+		key, err := jwk.publicKey()
+		if err != nil {
+			continue // one odd key shouldn't make the others unusable
+		}
+		keys[jwk.Kid] = jsonWebKey{alg: jwk.Alg, key: key}
+	}
+
+	k.mtx.Lock()
+	k.keys = keys
+	k.mtx.Unlock()
+	return nil
+}
+
+type jsonWebKey struct {
+	alg string
+	key interface{}
+}
+
+// jsonWebKeyJSON is the wire format of a public JSON Web Key.
+type jsonWebKeyJSON struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid,omitempty"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+func (jwk jsonWebKeyJSON) publicKey() (interface{}, error) {
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decodeBigInt(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return nil, ErrUnsupportedKey
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		curve, ok := curves[jwk.Crv]
+		if !ok {
+			return nil, ErrUnsupportedKey
+		}
+		x, err := decodeBigInt(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, ErrUnsupportedKey
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+
+	case "OKP":
+		if jwk.Crv != "Ed25519" {
+			return nil, ErrUnsupportedKey
+		}
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, ErrUnsupportedKey
+		}
+		return ed25519.PublicKey(x), nil
+
+	default:
+		return nil, ErrUnsupportedKey
+	}
+}
+
+var curves = map[string]elliptic.Curve{
+	"P-256": elliptic.P256(),
+	"P-384": elliptic.P384(),
+	"P-521": elliptic.P521(),
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, ErrUnsupportedKey
+	}
+	return new(big.Int).SetBytes(b), nil
+}
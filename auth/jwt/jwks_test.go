@@ -0,0 +1,156 @@
+package jwt
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"golang.org/x/crypto/ed25519"
+)
+
+func TestJWKS(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []SigningKey{
+		{KID: "rsa", Method: jwt.SigningMethodRS256, Key: rsaKey},
+		{KID: "ec", Method: jwt.SigningMethodES256, Key: ecKey},
+		{KID: "ed", Method: SigningMethodEdDSA, Key: edKey},
+	} {
+		t.Run(key.KID, func(t *testing.T) {
+			set := NewKeySet(key)
+			server := httptest.NewServer(set)
+			defer server.Close()
+
+			token := sign(t, set)
+			jwks := NewJWKS(server.URL)
+			parsed, err := parse(token, jwks.Keyfunc, key.Method)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want, have := "go-kit", parsed["user"]; want != have {
+				t.Errorf("want %q, have %q", want, have)
+			}
+
+			// The key must be used with the algorithm it's published for.
+			if _, err := parse(token, jwks.Keyfunc, jwt.SigningMethodHS256); err == nil {
+				t.Error("want error, have nil")
+			}
+		})
+	}
+}
+
+func TestJWKSRotation(t *testing.T) {
+	first, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		set     = NewKeySet(SigningKey{KID: "1", Method: jwt.SigningMethodES256, Key: first})
+		fetches uint64
+		server  = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddUint64(&fetches, 1)
+			set.ServeHTTP(w, r)
+		}))
+		jwks = NewJWKS(server.URL, JWKSRefreshInterval(50*time.Millisecond))
+	)
+	defer server.Close()
+
+	oldToken := sign(t, set)
+	if _, err := parse(oldToken, jwks.Keyfunc, jwt.SigningMethodES256); err != nil {
+		t.Fatal(err)
+	}
+
+	// A token signed with a new key triggers a fetch.
+	set.Rotate(SigningKey{KID: "2", Method: jwt.SigningMethodES384, Key: second})
+	time.Sleep(50 * time.Millisecond)
+	newToken := sign(t, set)
+	if _, err := parse(newToken, jwks.Keyfunc, jwt.SigningMethodES384); err != nil {
+		t.Fatal(err)
+	}
+	if want, have := uint64(2), atomic.LoadUint64(&fetches); want != have {
+		t.Errorf("want %d, have %d", want, have)
+	}
+
+	// Tokens signed with the previous key remain valid until it's retired.
+	if _, err := parse(oldToken, jwks.Keyfunc, jwt.SigningMethodES256); err != nil {
+		t.Fatal(err)
+	}
+	set.Retire("1")
+	if err := jwks.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parse(oldToken, jwks.Keyfunc, jwt.SigningMethodES256); err == nil {
+		t.Error("want error, have nil")
+	}
+
+	// Unknown key IDs don't trigger more than one fetch per interval.
+	before := atomic.LoadUint64(&fetches)
+	for i := 0; i < 10; i++ {
+		token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{})
+		token.Header["kid"] = "bogus"
+		s, _ := token.SignedString(first)
+		if _, err := parse(s, jwks.Keyfunc, jwt.SigningMethodES256); err == nil {
+			t.Error("want error, have nil")
+		}
+	}
+	if want, have := before, atomic.LoadUint64(&fetches); want != have {
+		t.Errorf("want %d, have %d", want, have)
+	}
+}
+
+func TestKeySetKeyfunc(t *testing.T) {
+	set := NewKeySet(SigningKey{KID: kid, Method: method, Key: key})
+	if _, err := parse(sign(t, set), set.Keyfunc, method); err != nil {
+		t.Fatal(err)
+	}
+
+	// Symmetric keys are never published.
+	rec := httptest.NewRecorder()
+	set.ServeHTTP(rec, httptest.NewRequest("GET", "/", nil))
+	if want, have := "{\"keys\":[]}\n", rec.Body.String(); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+}
+
+func sign(t *testing.T, set *KeySet) string {
+	e := func(ctx context.Context, i interface{}) (interface{}, error) { return ctx, nil }
+	ctx, err := NewKeySetSigner(set, mapClaims)(e)(context.Background(), struct{}{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ctx.(context.Context).Value(JWTTokenContextKey).(string)
+}
+
+func parse(token string, keyFunc jwt.Keyfunc, method jwt.SigningMethod) (jwt.MapClaims, error) {
+	e := func(ctx context.Context, i interface{}) (interface{}, error) { return ctx, nil }
+	ctx := context.WithValue(context.Background(), JWTTokenContextKey, token)
+	ctx1, err := NewParser(keyFunc, method, jwt.MapClaims{})(e)(ctx, struct{}{})
+	if err != nil {
+		return nil, err
+	}
+	return ctx1.(context.Context).Value(JWTClaimsContextKey).(jwt.MapClaims), nil
+}
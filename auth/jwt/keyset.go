@@ -0,0 +1,186 @@
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"sync"
+
+	jwt "github.com/dgrijalva/jwt-go"
+	"golang.org/x/crypto/ed25519"
+
+	"github.com/guherbozdogan/kit/endpoint"
+)
+
+// SigningKey is a key that tokens are signed with. Key must suit the signing
+// method: e.g. []byte for HMAC, *rsa.PrivateKey for RSA, *ecdsa.PrivateKey
+// for ECDSA, or ed25519.PrivateKey for SigningMethodEdDSA.
+type SigningKey struct {
+	KID    string
+	Method jwt.SigningMethod
+	Key    interface{}
+}
+
+// KeySet is a rotating set of signing keys. Tokens are always signed with the
+// current key. Keys that were rotated out remain valid for verification until
+// they're retired, so tokens issued before a rotation don't stop working
+// abruptly.
+type KeySet struct {
+	mtx  sync.RWMutex
+	keys []SigningKey // current key first
+}
+
+// NewKeySet returns a KeySet with the given current key.
+func NewKeySet(current SigningKey) *KeySet {
+	return &KeySet{keys: []SigningKey{current}}
+}
+
+// Rotate makes next the current key. The previous current key is kept for
+// verification.
+func (s *KeySet) Rotate(next SigningKey) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	keys := []SigningKey{next}
+	for _, key := range s.keys {
+		if key.KID != next.KID {
+			keys = append(keys, key)
+		}
+	}
+	s.keys = keys
+}
+
+// Retire removes a key that was rotated out. Tokens signed with it no longer
+// verify. The current key can't be retired.
+func (s *KeySet) Retire(kid string) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	keys := s.keys[:1:1]
+	for _, key := range s.keys[1:] {
+		if key.KID != kid {
+			keys = append(keys, key)
+		}
+	}
+	s.keys = keys
+}
+
+// Current returns the key that tokens are currently signed with.
+func (s *KeySet) Current() SigningKey {
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	return s.keys[0]
+}
+
+// Keyfunc implements jwt.Keyfunc, and can be passed to NewParser. It returns
+// the verification key for the token's key ID header, which is the public
+// half of asymmetric keys, provided the token's signing method matches the
+// key's.
+func (s *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	kid, ok := token.Header["kid"].(string)
+	if !ok || kid == "" {
+		return nil, ErrKIDMissing
+	}
+
+	s.mtx.RLock()
+	defer s.mtx.RUnlock()
+	for _, key := range s.keys {
+		if key.KID != kid {
+			continue
+		}
+		if key.Method.Alg() != token.Method.Alg() {
+			return nil, ErrUnexpectedSigningMethod
+		}
+		if signer, ok := key.Key.(crypto.Signer); ok {
+			return signer.Public(), nil
+		}
+		return key.Key, nil
+	}
+	return nil, ErrUnknownKID
+}
+
+// ServeHTTP publishes the public halves of the asymmetric keys in the set as a
+// JSON Web Key Set, so that other services can verify tokens with JWKS.
+// Symmetric keys are never published.
+func (s *KeySet) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var set struct {
+		Keys []jsonWebKeyJSON `json:"keys"`
+	}
+	set.Keys = []jsonWebKeyJSON{}
+
+	s.mtx.RLock()
+	for _, key := range s.keys {
+		signer, ok := key.Key.(crypto.Signer)
+		if !ok {
+			continue
+		}
+		jwk, ok := publicJSONWebKey(signer.Public())
+		if !ok {
+			continue
+		}
+		jwk.Kid, jwk.Use, jwk.Alg = key.KID, "sig", key.Method.Alg()
+		set.Keys = append(set.Keys, jwk)
+	}
+	s.mtx.RUnlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(set)
+}
+
+// NewKeySetSigner creates a new JWT token generating middleware like
+// NewSigner, but signs tokens with the current key of the key set, and sets
+// the key ID header (kid) accordingly.
+func NewKeySetSigner(s *KeySet, claims jwt.Claims) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (response interface{}, err error) {
+			key := s.Current()
+			token := jwt.NewWithClaims(key.Method, claims)
+			token.Header["kid"] = key.KID
+
+			tokenString, err := token.SignedString(key.Key)
+			if err != nil {
+				return nil, err
+			}
+			ctx = context.WithValue(ctx, JWTTokenContextKey, tokenString)
+
+			return next(ctx, request)
+		}
+	}
+}
+
+func publicJSONWebKey(pub crypto.PublicKey) (jsonWebKeyJSON, bool) {
+	enc := base64.RawURLEncoding.EncodeToString
+	switch pub := pub.(type) {
+	case *rsa.PublicKey:
+		return jsonWebKeyJSON{
+			Kty: "RSA",
+			N:   enc(pub.N.Bytes()),
+			E:   enc(big.NewInt(int64(pub.E)).Bytes()),
+		}, true
+	case *ecdsa.PublicKey:
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		return jsonWebKeyJSON{
+			Kty: "EC",
+			Crv: pub.Curve.Params().Name,
+			X:   enc(padBytes(pub.X, size)),
+			Y:   enc(padBytes(pub.Y, size)),
+		}, true
+	case ed25519.PublicKey:
+		return jsonWebKeyJSON{Kty: "OKP", Crv: "Ed25519", X: enc(pub)}, true
+	default:
+		return jsonWebKeyJSON{}, false
+	}
+}
+
+// padBytes returns the big-endian bytes of n, left-padded with zeros to size
+// bytes, as JWKs require for EC coordinates.
+func padBytes(n *big.Int, size int) []byte {
+	b := n.Bytes()
+	if len(b) >= size {
+		return b
+	}
+	return append(make([]byte, size-len(b)), b...)
+}
@@ -2,7 +2,10 @@ package jwt
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"reflect"
+	"time"
 
 	jwt "github.com/dgrijalva/jwt-go"
 
@@ -42,6 +45,16 @@ var (
 	// ErrUnexpectedSigningMethod denotes a token was signed with an unexpected
 	// signing method.
 	ErrUnexpectedSigningMethod = errors.New("unexpected signing method")
+
+	// ErrTokenAudience denotes a token's audience (aud) doesn't contain any of
+	// the expected audiences.
+	ErrTokenAudience = errors.New("JWT Token is not intended for this audience")
+
+	// ErrTokenIssuer denotes a token's issuer (iss) isn't the expected issuer.
+	ErrTokenIssuer = errors.New("JWT Token has an unexpected issuer")
+
+	// ErrTokenClaimMissing denotes a token lacks a required claim.
+	ErrTokenClaimMissing = errors.New("JWT Token is missing a required claim")
 )
 
 // NewSigner creates a new JWT token generating middleware, specifying key ID,
@@ -66,11 +79,64 @@ func NewSigner(kid string, key []byte, method jwt.SigningMethod, claims jwt.Clai
 	}
 }
 
+// ParserOption sets an optional parameter for parsers.
+type ParserOption func(*parserOptions)
+
+type parserOptions struct {
+	newClaims func() jwt.Claims
+	audience  []string
+	issuer    string
+	required  []string
+	leeway    time.Duration
+}
+
+// ParserAudience requires the token's audience (aud) to contain at least one
+// of the given audiences.
+func ParserAudience(aud ...string) ParserOption {
+	return func(o *parserOptions) { o.audience = aud }
+}
+
+// ParserIssuer requires the token's issuer (iss) to be the given issuer.
+func ParserIssuer(iss string) ParserOption {
+	return func(o *parserOptions) { o.issuer = iss }
+}
+
+// ParserRequiredClaims requires the token to contain all of the named claims,
+// e.g. "exp" or "sub".
+func ParserRequiredClaims(names ...string) ParserOption {
+	return func(o *parserOptions) { o.required = names }
+}
+
+// ParserLeeway allows for clock skew between the token issuer and the parser
+// when validating the time based claims exp, nbf and iat. If a leeway is set,
+// these claims are validated by the parser, and time based errors returned by
+// the claims' Valid method are ignored; any other error still fails the token.
+func ParserLeeway(d time.Duration) ParserOption {
+	return func(o *parserOptions) { o.leeway = d }
+}
+
+// ParserClaimsFactory sets the function which creates the claims value that
+// each token is parsed into. It must return a fresh value on every call, as the
+// value is stored in the request context.
+func ParserClaimsFactory(f func() jwt.Claims) ParserOption {
+	return func(o *parserOptions) { o.newClaims = f }
+}
+
 // NewParser creates a new JWT token parsing middleware, specifying a
 // jwt.Keyfunc interface, the signing method and the claims type to be used. NewParser
 // adds the resulting  claims to endpoint context or returns error on invalid token.
 // Particularly useful for servers.
-func NewParser(keyFunc jwt.Keyfunc, method jwt.SigningMethod, claims jwt.Claims) endpoint.Middleware {
+//
+// The claims are a prototype: each token is parsed into a fresh value of the
+// same type, so that concurrent requests don't share claims. Use
+// ParserClaimsFactory if the claims need to be initialized differently.
+func NewParser(keyFunc jwt.Keyfunc, method jwt.SigningMethod, claims jwt.Claims, options ...ParserOption) endpoint.Middleware {
+	o := parserOptions{newClaims: claimsFactory(claims)}
+	for _, option := range options {
+		option(&o)
+	}
+	parser := &jwt.Parser{SkipClaimsValidation: o.leeway > 0}
+
 	return func(next endpoint.Endpoint) endpoint.Endpoint {
 		return func(ctx context.Context, request interface{}) (response interface{}, err error) {
 			// tokenString is stored in the context from the transport handlers.
@@ -85,7 +151,7 @@ func NewParser(keyFunc jwt.Keyfunc, method jwt.SigningMethod, claims jwt.Claims)
 			// of the token to identify which key to use, but the parsed token
 			// (head and claims) is provided to the callback, providing
 			// flexibility.
-			token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+			token, err := parser.ParseWithClaims(tokenString, o.newClaims(), func(token *jwt.Token) (interface{}, error) {
 				// Don't forget to validate the alg is what you expect:
 				if token.Method != method {
 					return nil, ErrUnexpectedSigningMethod
@@ -116,9 +182,116 @@ func NewParser(keyFunc jwt.Keyfunc, method jwt.SigningMethod, claims jwt.Claims)
 				return nil, ErrTokenInvalid
 			}
 
+			if err := o.validate(token.Claims); err != nil {
+				return nil, err
+			}
+
 			ctx = context.WithValue(ctx, JWTClaimsContextKey, token.Claims)
 
 			return next(ctx, request)
 		}
 	}
 }
+
+// validate enforces the parser options on the claims.
+func (o parserOptions) validate(claims jwt.Claims) error {
+	if o.leeway <= 0 && len(o.audience) == 0 && o.issuer == "" && len(o.required) == 0 {
+		return nil
+	}
+	if o.leeway > 0 {
+		// The jwt parser skips validation if a leeway is set.
+		if err := validClaims(claims); err != nil {
+			return err
+		}
+	}
+
+	m, err := toMapClaims(claims)
+	if err != nil {
+		return err
+	}
+
+	if o.leeway > 0 {
+		var (
+			now    = float64(time.Now().Unix())
+			leeway = o.leeway.Seconds()
+		)
+		if exp, ok := numericClaim(m["exp"]); ok && now > exp+leeway {
+			return ErrTokenExpired
+		}
+		if nbf, ok := numericClaim(m["nbf"]); ok && now+leeway < nbf {
+			return ErrTokenNotActive
+		}
+		if iat, ok := numericClaim(m["iat"]); ok && now+leeway < iat {
+			return ErrTokenNotActive
+		}
+	}
+
+	if len(o.audience) > 0 {
+		var (
+			have = claimStrings(m["aud"], false)
+			ok   bool
+		)
+		for _, aud := range o.audience {
+			ok = ok || contains(have, aud)
+		}
+		if !ok {
+			return ErrTokenAudience
+		}
+	}
+
+	if o.issuer != "" {
+		if iss, _ := m["iss"].(string); iss != o.issuer {
+			return ErrTokenIssuer
+		}
+	}
+
+	for _, name := range o.required {
+		if _, ok := m[name]; !ok {
+			return ErrTokenClaimMissing
+		}
+	}
+	return nil
+}
+
+// validClaims calls the claims' Valid method, ignoring the errors of the time
+// based checks, which validate repeats with the leeway.
+func validClaims(claims jwt.Claims) error {
+	err := claims.Valid()
+	e, ok := err.(*jwt.ValidationError)
+	if !ok {
+		return err
+	}
+	const timeErrors = jwt.ValidationErrorExpired | jwt.ValidationErrorNotValidYet | jwt.ValidationErrorIssuedAt
+	if e.Errors&^timeErrors == 0 {
+		return nil
+	}
+	if e.Inner != nil {
+		return e.Inner
+	}
+	return e
+}
+
+// claimsFactory returns a function which creates fresh claims of the same type
+// as the prototype. Claims that aren't maps or pointers can't be parsed into,
+// so they're returned as they are.
+func claimsFactory(prototype jwt.Claims) func() jwt.Claims {
+	if _, ok := prototype.(jwt.MapClaims); ok {
+		return func() jwt.Claims { return jwt.MapClaims{} }
+	}
+	if t := reflect.TypeOf(prototype); t != nil && t.Kind() == reflect.Ptr {
+		return func() jwt.Claims { return reflect.New(t.Elem()).Interface().(jwt.Claims) }
+	}
+	return func() jwt.Claims { return prototype }
+}
+
+func numericClaim(v interface{}) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
@@ -2,7 +2,9 @@ package jwt
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"crypto/subtle"
 
@@ -161,3 +163,113 @@ func TestJWTParser(t *testing.T) {
 		t.Fatalf("JWT customClaims.MyProperty did not match: expecting %s got %s", myProperty, custCl.MyProperty)
 	}
 }
+
+func TestJWTParserOptions(t *testing.T) {
+	e := func(ctx context.Context, i interface{}) (interface{}, error) { return ctx, nil }
+	keys := func(token *jwt.Token) (interface{}, error) { return key, nil }
+	now := time.Now().Unix()
+
+	for _, tc := range []struct {
+		name    string
+		claims  jwt.MapClaims
+		options []ParserOption
+		want    error
+	}{
+		{"audience", jwt.MapClaims{"aud": "a"}, []ParserOption{ParserAudience("b", "a")}, nil},
+		{"audience list", jwt.MapClaims{"aud": []string{"a", "b"}}, []ParserOption{ParserAudience("b")}, nil},
+		{"wrong audience", jwt.MapClaims{"aud": "a"}, []ParserOption{ParserAudience("b")}, ErrTokenAudience},
+		{"issuer", jwt.MapClaims{"iss": "i"}, []ParserOption{ParserIssuer("i")}, nil},
+		{"wrong issuer", jwt.MapClaims{"iss": "i"}, []ParserOption{ParserIssuer("j")}, ErrTokenIssuer},
+		{"required", jwt.MapClaims{"sub": "s"}, []ParserOption{ParserRequiredClaims("sub")}, nil},
+		{"missing required", jwt.MapClaims{"sub": "s"}, []ParserOption{ParserRequiredClaims("sub", "exp")}, ErrTokenClaimMissing},
+		{"expired", jwt.MapClaims{"exp": now - 5}, nil, ErrTokenExpired},
+		{"expired within leeway", jwt.MapClaims{"exp": now - 5}, []ParserOption{ParserLeeway(time.Minute)}, nil},
+		{"expired beyond leeway", jwt.MapClaims{"exp": now - 120}, []ParserOption{ParserLeeway(time.Minute)}, ErrTokenExpired},
+		{"not active within leeway", jwt.MapClaims{"nbf": now + 5}, []ParserOption{ParserLeeway(time.Minute)}, nil},
+		{"not active beyond leeway", jwt.MapClaims{"nbf": now + 120}, []ParserOption{ParserLeeway(time.Minute)}, ErrTokenNotActive},
+	} {
+		signer := NewSigner(kid, key, method, tc.claims)(e)
+		ctx, err := signer(context.Background(), struct{}{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		parser := NewParser(keys, method, jwt.MapClaims{}, tc.options...)(e)
+		if _, have := parser(ctx.(context.Context), struct{}{}); tc.want != have {
+			t.Errorf("%s: want %v, have %v", tc.name, tc.want, have)
+		}
+	}
+}
+
+var errInvalidRole = errors.New("invalid role")
+
+type roleClaims struct {
+	Role string `json:"role"`
+	jwt.StandardClaims
+}
+
+func (c *roleClaims) Valid() error {
+	if err := c.StandardClaims.Valid(); err != nil {
+		return err
+	}
+	if c.Role != "admin" {
+		return errInvalidRole
+	}
+	return nil
+}
+
+func TestJWTParserLeewayCustomClaims(t *testing.T) {
+	e := func(ctx context.Context, i interface{}) (interface{}, error) { return ctx, nil }
+	keys := func(token *jwt.Token) (interface{}, error) { return key, nil }
+	now := time.Now().Unix()
+
+	for _, tc := range []struct {
+		name   string
+		claims *roleClaims
+		want   error
+	}{
+		{"valid", &roleClaims{Role: "admin"}, nil},
+		{"invalid", &roleClaims{Role: "user"}, errInvalidRole},
+		{"expired within leeway", &roleClaims{Role: "admin", StandardClaims: jwt.StandardClaims{ExpiresAt: now - 5}}, nil},
+		{"expired beyond leeway", &roleClaims{Role: "admin", StandardClaims: jwt.StandardClaims{ExpiresAt: now - 120}}, ErrTokenExpired},
+	} {
+		signer := NewSigner(kid, key, method, tc.claims)(e)
+		ctx, err := signer(context.Background(), struct{}{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		parser := NewParser(keys, method, &roleClaims{}, ParserLeeway(time.Minute))(e)
+		if _, have := parser(ctx.(context.Context), struct{}{}); tc.want != have {
+			t.Errorf("%s: want %v, have %v", tc.name, tc.want, have)
+		}
+	}
+}
+
+func TestJWTParserFreshClaims(t *testing.T) {
+	e := func(ctx context.Context, i interface{}) (interface{}, error) { return ctx, nil }
+	keys := func(token *jwt.Token) (interface{}, error) { return key, nil }
+	ctx := context.WithValue(context.Background(), JWTTokenContextKey, standardSignedKey)
+
+	for _, parser := range []endpoint.Endpoint{
+		NewParser(keys, method, &jwt.StandardClaims{})(e),
+		NewParser(keys, method, nil, ParserClaimsFactory(func() jwt.Claims { return &jwt.StandardClaims{} }))(e),
+	} {
+		ctx1, err := parser(ctx, struct{}{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx2, err := parser(ctx, struct{}{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		cl1 := ctx1.(context.Context).Value(JWTClaimsContextKey).(*jwt.StandardClaims)
+		cl2 := ctx2.(context.Context).Value(JWTClaimsContextKey).(*jwt.StandardClaims)
+		if cl1 == cl2 {
+			t.Error("claims are shared between requests")
+		}
+		if want, have := "go-kit", cl2.Audience; want != have {
+			t.Errorf("want %q, have %q", want, have)
+		}
+	}
+}
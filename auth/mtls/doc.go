@@ -0,0 +1,5 @@
+// Package mtls authenticates and authorizes callers by the client certificates
+// they present over mutual TLS. Transport request funcs extract the identity
+// of the verified peer certificate into the context, and an endpoint
+// middleware checks it against an allowlist.
+package mtls
@@ -0,0 +1,49 @@
+package mtls
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"strings"
+)
+
+type contextKey string
+
+// IdentityContextKey holds the key used to store the Identity of the peer in
+// the context.
+const IdentityContextKey contextKey = "PeerIdentity"
+
+// Identity describes the verified certificate a peer presented.
+type Identity struct {
+	Subject    string   // e.g. "CN=billing,O=Example"
+	CommonName string   // subject common name
+	DNSNames   []string // DNS subject alternative names
+	URIs       []string // URI subject alternative names
+	SPIFFEID   string   // the URI SAN with the spiffe scheme, if any
+}
+
+// IdentityFromCertificate returns the Identity of a certificate.
+func IdentityFromCertificate(cert *x509.Certificate) Identity {
+	id := Identity{
+		Subject:    cert.Subject.String(),
+		CommonName: cert.Subject.CommonName,
+		DNSNames:   cert.DNSNames,
+	}
+	for _, uri := range cert.URIs {
+		s := uri.String()
+		id.URIs = append(id.URIs, s)
+		if strings.EqualFold(uri.Scheme, "spiffe") && id.SPIFFEID == "" {
+			id.SPIFFEID = s
+		}
+	}
+	return id
+}
+
+// identityFromState returns the Identity of the verified peer certificate of a
+// TLS connection. Unverified certificates, which a server configured with
+// tls.RequestClientCert accepts, are ignored.
+func identityFromState(state *tls.ConnectionState) (Identity, bool) {
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return Identity{}, false
+	}
+	return IdentityFromCertificate(state.VerifiedChains[0][0]), true
+}
@@ -0,0 +1,111 @@
+package mtls
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/guherbozdogan/kit/endpoint"
+)
+
+var (
+	// ErrIdentityMissing denotes no peer identity was passed into the
+	// middleware's context, e.g. because the peer presented no verified
+	// certificate. It maps to HTTP status 401 and the gRPC Unauthenticated
+	// code.
+	ErrIdentityMissing error = &identityError{"peer identity missing", http.StatusUnauthorized, codes.Unauthenticated}
+
+	// ErrIdentityNotAllowed denotes the peer identity isn't on the allowlist.
+	// It maps to HTTP status 403 and the gRPC PermissionDenied code.
+	ErrIdentityNotAllowed error = &identityError{"peer identity not allowed", http.StatusForbidden, codes.PermissionDenied}
+)
+
+type identityError struct {
+	msg  string
+	code int
+	grpc codes.Code
+}
+
+func (e *identityError) Error() string              { return e.msg }
+func (e *identityError) StatusCode() int            { return e.code }
+func (e *identityError) GRPCStatus() *status.Status { return status.New(e.grpc, e.msg) }
+
+// NewAllowlist returns a middleware which only lets requests pass whose peer
+// Identity, as stored by ToHTTPContext or ToGRPCContext, is known by one of the
+// allowed names. Each name is matched exactly against one kind of name of the
+// peer certificate, depending on its form: names with the spiffe scheme
+// against the SPIFFE ID, other URIs against the URI SANs, and all other names
+// against the DNS SANs. The common name is never matched; see
+// NewCommonNameAllowlist.
+func NewAllowlist(allowed ...string) endpoint.Middleware {
+	var spiffeIDs, uris, dnsNames nameSet
+	for _, name := range allowed {
+		switch {
+		case strings.HasPrefix(strings.ToLower(name), "spiffe://"):
+			spiffeIDs = spiffeIDs.add(name)
+		case strings.Contains(name, ":"):
+			uris = uris.add(name)
+		default:
+			dnsNames = dnsNames.add(name)
+		}
+	}
+	return allowlist(func(id Identity) bool {
+		return spiffeIDs.has(id.SPIFFEID) || uris.has(id.URIs...) || dnsNames.has(id.DNSNames...)
+	})
+}
+
+// NewCommonNameAllowlist returns a middleware which only lets requests pass
+// whose peer Identity has one of the allowed common names. The common name
+// isn't typed, so a CA that signs certificates with arbitrary subjects lets any
+// of them pass: only use it for legacy certificates without SANs, issued by a
+// CA that is trusted for exactly that purpose.
+func NewCommonNameAllowlist(allowed ...string) endpoint.Middleware {
+	var commonNames nameSet
+	for _, name := range allowed {
+		commonNames = commonNames.add(name)
+	}
+	return allowlist(func(id Identity) bool {
+		return commonNames.has(id.CommonName)
+	})
+}
+
+func allowlist(allow func(Identity) bool) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			id, ok := ctx.Value(IdentityContextKey).(Identity)
+			if !ok {
+				return nil, ErrIdentityMissing
+			}
+			if !allow(id) {
+				return nil, ErrIdentityNotAllowed
+			}
+			return next(ctx, request)
+		}
+	}
+}
+
+// nameSet is a set of non-empty names.
+type nameSet map[string]struct{}
+
+func (s nameSet) add(name string) nameSet {
+	if name == "" {
+		return s
+	}
+	if s == nil {
+		s = nameSet{}
+	}
+	s[name] = struct{}{}
+	return s
+}
+
+func (s nameSet) has(names ...string) bool {
+	for _, name := range names {
+		if _, ok := s[name]; ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,155 @@
+package mtls
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+func newCertificate(t *testing.T) *x509.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	spiffe, _ := url.Parse("spiffe://example.org/billing")
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "billing", Organization: []string{"Example"}},
+		DNSNames:     []string{"billing.example.org"},
+		URIs:         []*url.URL{spiffe},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func TestToHTTPContext(t *testing.T) {
+	cert := newCertificate(t)
+
+	// Unverified certificates are ignored.
+	r := &http.Request{TLS: &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}}
+	if ctx := ToHTTPContext()(context.Background(), r); ctx.Value(IdentityContextKey) != nil {
+		t.Error("Context shouldn't contain an identity")
+	}
+
+	r.TLS.VerifiedChains = [][]*x509.Certificate{{cert}}
+	id, ok := ToHTTPContext()(context.Background(), r).Value(IdentityContextKey).(Identity)
+	if !ok {
+		t.Fatal("Context doesn't contain an identity")
+	}
+	if want, have := "spiffe://example.org/billing", id.SPIFFEID; want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+	if want, have := "billing", id.CommonName; want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+	if want, have := "CN=billing,O=Example", id.Subject; want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+	if want, have := []string{"spiffe://example.org/billing"}, id.URIs; fmt.Sprint(want) != fmt.Sprint(have) {
+		t.Errorf("want %v, have %v", want, have)
+	}
+}
+
+func TestToGRPCContext(t *testing.T) {
+	cert := newCertificate(t)
+	ctx := peer.NewContext(context.Background(), &peer.Peer{
+		AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{
+			VerifiedChains: [][]*x509.Certificate{{cert}},
+		}},
+	})
+
+	id, ok := ToGRPCContext()(ctx, metadata.MD{}).Value(IdentityContextKey).(Identity)
+	if !ok {
+		t.Fatal("Context doesn't contain an identity")
+	}
+	if want, have := "billing.example.org", id.DNSNames[0]; want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+
+	// Peers without TLS are ignored.
+	ctx = peer.NewContext(context.Background(), &peer.Peer{})
+	if ctx := ToGRPCContext()(ctx, metadata.MD{}); ctx.Value(IdentityContextKey) != nil {
+		t.Error("Context shouldn't contain an identity")
+	}
+}
+
+func TestAllowlist(t *testing.T) {
+	var (
+		e  = func(context.Context, interface{}) (interface{}, error) { return struct{}{}, nil }
+		id = IdentityFromCertificate(newCertificate(t))
+	)
+
+	for _, tc := range []struct {
+		allowed []string
+		ctx     context.Context
+		want    error
+	}{
+		{[]string{"spiffe://example.org/billing"}, context.WithValue(context.Background(), IdentityContextKey, id), nil},
+		{[]string{"other", "billing.example.org"}, context.WithValue(context.Background(), IdentityContextKey, id), nil},
+		{[]string{"billing"}, context.WithValue(context.Background(), IdentityContextKey, id), ErrIdentityNotAllowed},
+		{[]string{"spiffe://example.org/orders"}, context.WithValue(context.Background(), IdentityContextKey, id), ErrIdentityNotAllowed},
+		{[]string{"billing"}, context.Background(), ErrIdentityMissing},
+	} {
+		if _, have := NewAllowlist(tc.allowed...)(e)(tc.ctx, struct{}{}); tc.want != have {
+			t.Errorf("%v: want %v, have %v", tc.allowed, tc.want, have)
+		}
+	}
+
+	if _, have := NewCommonNameAllowlist("billing")(e)(context.WithValue(context.Background(), IdentityContextKey, id), struct{}{}); have != nil {
+		t.Errorf("common name: want %v, have %v", nil, have)
+	}
+	if want, have := codes.PermissionDenied, grpc.Code(ErrIdentityNotAllowed); want != have {
+		t.Errorf("want %s, have %s", want, have)
+	}
+	if want, have := http.StatusUnauthorized, ErrIdentityMissing.(interface{ StatusCode() int }).StatusCode(); want != have {
+		t.Errorf("want %d, have %d", want, have)
+	}
+}
+
+func TestAllowlistCommonNameImpersonation(t *testing.T) {
+	e := func(context.Context, interface{}) (interface{}, error) { return struct{}{}, nil }
+
+	// A certificate without SANs, whose common name claims to be another
+	// SPIFFE ID, URI or DNS name, must not be allowed.
+	for _, name := range []string{"spiffe://example.org/billing", "https://billing.example.org", "billing.example.org"} {
+		id := Identity{CommonName: name}
+		ctx := context.WithValue(context.Background(), IdentityContextKey, id)
+		if _, have := NewAllowlist(name)(e)(ctx, struct{}{}); have != ErrIdentityNotAllowed {
+			t.Errorf("%s: want %v, have %v", name, ErrIdentityNotAllowed, have)
+		}
+	}
+
+	// Neither may a SPIFFE ID pass as a DNS name, or the other way around.
+	id := Identity{URIs: []string{"spiffe://example.org/billing"}, SPIFFEID: "spiffe://example.org/billing", DNSNames: []string{"billing.example.org"}}
+	ctx := context.WithValue(context.Background(), IdentityContextKey, id)
+	for _, name := range []string{"spiffe://billing.example.org", "example.org/billing"} {
+		if _, have := NewAllowlist(name)(e)(ctx, struct{}{}); have != ErrIdentityNotAllowed {
+			t.Errorf("%s: want %v, have %v", name, ErrIdentityNotAllowed, have)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package mtls
+
+import (
+	"context"
+	stdhttp "net/http"
+
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	"github.com/guherbozdogan/kit/transport/grpc"
+	"github.com/guherbozdogan/kit/transport/http"
+)
+
+// ToHTTPContext moves the identity of the verified client certificate of the
+// request's TLS connection to the context. Particularly useful for servers.
+func ToHTTPContext() http.RequestFunc {
+	return func(ctx context.Context, r *stdhttp.Request) context.Context {
+		id, ok := identityFromState(r.TLS)
+		if !ok {
+			return ctx
+		}
+		return context.WithValue(ctx, IdentityContextKey, id)
+	}
+}
+
+// ToGRPCContext moves the identity of the verified client certificate of the
+// peer's TLS connection to the context. The server must use TLS transport
+// credentials. Particularly useful for servers.
+func ToGRPCContext() grpc.ServerRequestFunc {
+	return func(ctx context.Context, _ metadata.MD) context.Context {
+		p, ok := peer.FromContext(ctx)
+		if !ok {
+			return ctx
+		}
+		info, ok := p.AuthInfo.(credentials.TLSInfo)
+		if !ok {
+			return ctx
+		}
+		id, ok := identityFromState(&info.State)
+		if !ok {
+			return ctx
+		}
+		return context.WithValue(ctx, IdentityContextKey, id)
+	}
+}
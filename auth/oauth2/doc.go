@@ -0,0 +1,5 @@
+// Package oauth2 authenticates outgoing client requests with access tokens
+// obtained from an OAuth2 authorization server with the client credentials
+// grant (RFC 6749, section 4.4). Tokens are cached and refreshed before they
+// expire.
+package oauth2
@@ -0,0 +1,69 @@
+package oauth2
+
+import (
+	"context"
+	"net/http"
+
+	"golang.org/x/oauth2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	"github.com/guherbozdogan/kit/endpoint"
+)
+
+type contextKey string
+
+// TokenContextKey holds the key used to store the *oauth2.Token for an outgoing
+// request in the context.
+const TokenContextKey contextKey = "OAuth2Token"
+
+// NewClient returns a client middleware which obtains an access token from the
+// token source, and adds it to the context via TokenContextKey, where
+// FromHTTPContext or FromGRPCContext pick it up. If the request fails because
+// the server rejected the token, the token is invalidated, and the request is
+// retried once with a new token.
+//
+// By default, errors implementing transport/http.StatusCoder with status 401,
+// and gRPC errors with the Unauthenticated code are considered rejections; use
+// NewClientWithRejection for other transports or error encodings.
+func NewClient(s *TokenSource) endpoint.Middleware {
+	return NewClientWithRejection(s, IsRejected)
+}
+
+// NewClientWithRejection is like NewClient, but uses the given predicate to
+// recognize errors that indicate the server rejected the token.
+func NewClientWithRejection(s *TokenSource, rejected func(error) bool) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			token, err := s.Token(ctx)
+			if err != nil {
+				return nil, err
+			}
+			response, err := next(context.WithValue(ctx, TokenContextKey, token), request)
+			if err == nil || !rejected(err) {
+				return response, err
+			}
+
+			s.Invalidate(token)
+			if token, err = s.Token(ctx); err != nil {
+				return nil, err
+			}
+			return next(context.WithValue(ctx, TokenContextKey, token), request)
+		}
+	}
+}
+
+// IsRejected returns true for errors implementing transport/http.StatusCoder
+// with status 401, and gRPC errors with the Unauthenticated code.
+func IsRejected(err error) bool {
+	if sc, ok := err.(interface {
+		StatusCode() int
+	}); ok && sc.StatusCode() == http.StatusUnauthorized {
+		return true
+	}
+	return grpc.Code(err) == codes.Unauthenticated
+}
+
+func authHeader(token *oauth2.Token) string {
+	return token.Type() + " " + token.AccessToken
+}
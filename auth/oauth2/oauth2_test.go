@@ -0,0 +1,152 @@
+package oauth2
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2/clientcredentials"
+	"google.golang.org/grpc/metadata"
+)
+
+func newTokenServer(t *testing.T, fetches *uint64) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Fatal(err)
+		}
+		if want, have := "client_credentials", r.Form.Get("grant_type"); want != have {
+			t.Errorf("want %q, have %q", want, have)
+		}
+		n := atomic.AddUint64(fetches, 1)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token":"token-%d","token_type":"bearer","expires_in":3600}`, n)
+	}))
+}
+
+func newTestTokenSource(url string) *TokenSource {
+	return NewTokenSource(clientcredentials.Config{
+		ClientID:     "id",
+		ClientSecret: "secret",
+		TokenURL:     url,
+	})
+}
+
+func TestTokenSource(t *testing.T) {
+	var fetches uint64
+	server := newTokenServer(t, &fetches)
+	defer server.Close()
+	s := newTestTokenSource(server.URL)
+
+	for i := 0; i < 3; i++ {
+		token, err := s.Token(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want, have := "token-1", token.AccessToken; want != have {
+			t.Errorf("want %q, have %q", want, have)
+		}
+	}
+
+	// Tokens are refreshed before they expire.
+	s.now = func() time.Time { return time.Now().Add(time.Hour - DefaultExpiryDelta/2) }
+	token, err := s.Token(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, have := "token-2", token.AccessToken; want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+
+	// Stale invalidations are ignored.
+	s.now = time.Now
+	s.Invalidate(nil)
+	if token, _ = s.Token(context.Background()); token.AccessToken != "token-2" {
+		t.Errorf("want %q, have %q", "token-2", token.AccessToken)
+	}
+	s.Invalidate(token)
+	if token, _ = s.Token(context.Background()); token.AccessToken != "token-3" {
+		t.Errorf("want %q, have %q", "token-3", token.AccessToken)
+	}
+}
+
+type statusError int
+
+func (e statusError) Error() string   { return http.StatusText(int(e)) }
+func (e statusError) StatusCode() int { return int(e) }
+
+func TestClientRetriesOnce(t *testing.T) {
+	var fetches uint64
+	server := newTokenServer(t, &fetches)
+	defer server.Close()
+	s := newTestTokenSource(server.URL)
+
+	// The server rejects the first token only.
+	var calls int
+	e := NewClient(s)(func(ctx context.Context, _ interface{}) (interface{}, error) {
+		calls++
+		r, _ := http.NewRequest("GET", "/", nil)
+		FromHTTPContext()(ctx, r)
+		if r.Header.Get("Authorization") == "Bearer token-1" {
+			return nil, statusError(http.StatusUnauthorized)
+		}
+		return r.Header.Get("Authorization"), nil
+	})
+	response, err := e(context.Background(), struct{}{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, have := "Bearer token-2", response; want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+	if want, have := 2, calls; want != have {
+		t.Errorf("want %d, have %d", want, have)
+	}
+
+	// A server that always rejects tokens gets a single retry.
+	calls = 0
+	e = NewClient(s)(func(context.Context, interface{}) (interface{}, error) {
+		calls++
+		return nil, statusError(http.StatusUnauthorized)
+	})
+	if _, err := e(context.Background(), struct{}{}); err == nil {
+		t.Error("want error, have nil")
+	}
+	if want, have := 2, calls; want != have {
+		t.Errorf("want %d, have %d", want, have)
+	}
+
+	// Other errors aren't retried.
+	calls = 0
+	e = NewClient(s)(func(context.Context, interface{}) (interface{}, error) {
+		calls++
+		return nil, errors.New("boom")
+	})
+	e(context.Background(), struct{}{})
+	if want, have := 1, calls; want != have {
+		t.Errorf("want %d, have %d", want, have)
+	}
+}
+
+func TestTokenRequestFuncs(t *testing.T) {
+	var fetches uint64
+	server := newTokenServer(t, &fetches)
+	defer server.Close()
+	s := newTestTokenSource(server.URL)
+
+	r, _ := http.NewRequest("GET", "/", nil)
+	TokenHTTPRequestFunc(s)(context.Background(), r)
+	if want, have := "Bearer token-1", r.Header.Get("Authorization"); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+
+	md := metadata.MD{}
+	TokenGRPCRequestFunc(s)(context.Background(), &md)
+	if want, have := "Bearer token-1", md["authorization"][0]; want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+}
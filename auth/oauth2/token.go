@@ -0,0 +1,100 @@
+package oauth2
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+// DefaultExpiryDelta is how long before expiry tokens are refreshed, unless
+// configured otherwise.
+const DefaultExpiryDelta = 10 * time.Second
+
+// TokenSource obtains access tokens with the client credentials grant, and
+// caches them until shortly before they expire. It's safe for concurrent use;
+// concurrent callers share a single fetch.
+type TokenSource struct {
+	config      clientcredentials.Config
+	client      *http.Client
+	expiryDelta time.Duration
+	now         func() time.Time
+
+	mtx   sync.Mutex
+	token *oauth2.Token
+}
+
+// TokenSourceOption sets an optional parameter for token sources.
+type TokenSourceOption func(*TokenSource)
+
+// TokenSourceHTTPClient sets the HTTP client used to request tokens. By
+// default, http.DefaultClient is used.
+func TokenSourceHTTPClient(client *http.Client) TokenSourceOption {
+	return func(s *TokenSource) { s.client = client }
+}
+
+// TokenSourceExpiryDelta sets how long before expiry tokens are refreshed. By
+// default, DefaultExpiryDelta is used.
+func TokenSourceExpiryDelta(d time.Duration) TokenSourceOption {
+	return func(s *TokenSource) { s.expiryDelta = d }
+}
+
+// NewTokenSource returns a TokenSource for the client credentials in the
+// config. The config's TokenURL is the token endpoint of the authorization
+// server.
+func NewTokenSource(config clientcredentials.Config, options ...TokenSourceOption) *TokenSource {
+	s := &TokenSource{
+		config:      config,
+		expiryDelta: DefaultExpiryDelta,
+		now:         time.Now,
+	}
+	for _, option := range options {
+		option(s)
+	}
+	return s
+}
+
+// Token returns the cached token, or fetches a new one if there's no cached
+// token or it's about to expire.
+func (s *TokenSource) Token(ctx context.Context) (*oauth2.Token, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if s.valid(s.token) {
+		return s.token, nil
+	}
+	if s.client != nil {
+		ctx = context.WithValue(ctx, oauth2.HTTPClient, s.client)
+	}
+	token, err := s.config.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s.token = token
+	return token, nil
+}
+
+// Invalidate drops the cached token if it's the given token, so that the next
+// call to Token fetches a new one. It's typically called when a server rejects
+// the token. If the token was replaced in the meantime, e.g. by a concurrent
+// request, nothing happens.
+func (s *TokenSource) Invalidate(token *oauth2.Token) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	if s.token == token {
+		s.token = nil
+	}
+}
+
+func (s *TokenSource) valid(token *oauth2.Token) bool {
+	if token == nil || token.AccessToken == "" {
+		return false
+	}
+	if token.Expiry.IsZero() {
+		return true
+	}
+	return s.now().Add(s.expiryDelta).Before(token.Expiry)
+}
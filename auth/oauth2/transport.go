@@ -0,0 +1,61 @@
+package oauth2
+
+import (
+	"context"
+	stdhttp "net/http"
+
+	"golang.org/x/oauth2"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/guherbozdogan/kit/transport/grpc"
+	"github.com/guherbozdogan/kit/transport/http"
+)
+
+// FromHTTPContext moves the access token from the context to the request
+// header. Use it as a ClientBefore option together with NewClient.
+func FromHTTPContext() http.RequestFunc {
+	return func(ctx context.Context, r *stdhttp.Request) context.Context {
+		if token, ok := ctx.Value(TokenContextKey).(*oauth2.Token); ok {
+			r.Header.Set("Authorization", authHeader(token))
+		}
+		return ctx
+	}
+}
+
+// FromGRPCContext moves the access token from the context to grpc metadata.
+// Use it as a ClientBefore option together with NewClient.
+func FromGRPCContext() grpc.ClientRequestFunc {
+	return func(ctx context.Context, md *metadata.MD) context.Context {
+		if token, ok := ctx.Value(TokenContextKey).(*oauth2.Token); ok {
+			// capital "Key" is illegal in HTTP/2.
+			(*md)["authorization"] = []string{authHeader(token)}
+		}
+		return ctx
+	}
+}
+
+// TokenHTTPRequestFunc obtains an access token from the token source and sets
+// it on the request header, for use as a ClientBefore option without NewClient.
+// Since request funcs can't fail, the request is sent without a token if none
+// can be obtained, and rejected tokens aren't retried; prefer NewClient.
+func TokenHTTPRequestFunc(s *TokenSource) http.RequestFunc {
+	return func(ctx context.Context, r *stdhttp.Request) context.Context {
+		if token, err := s.Token(ctx); err == nil {
+			r.Header.Set("Authorization", authHeader(token))
+		}
+		return ctx
+	}
+}
+
+// TokenGRPCRequestFunc obtains an access token from the token source and sets
+// it in grpc metadata, for use as a ClientBefore option without NewClient. The
+// caveats of TokenHTTPRequestFunc apply.
+func TokenGRPCRequestFunc(s *TokenSource) grpc.ClientRequestFunc {
+	return func(ctx context.Context, md *metadata.MD) context.Context {
+		if token, err := s.Token(ctx); err == nil {
+			// capital "Key" is illegal in HTTP/2.
+			(*md)["authorization"] = []string{authHeader(token)}
+		}
+		return ctx
+	}
+}
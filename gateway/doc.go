@@ -0,0 +1,26 @@
+// Package gateway builds API gateways from a route table.
+//
+// Each route maps a path prefix to a service in a service discovery system.
+// The gateway subscribes to the service, balances requests over its instances
+// and retries failed requests on other instances. Routes with a transport, like
+// HTTPTransport or GRPCTransport, decode gateway requests with their codec and
+// call an endpoint on the instances; routes without a transport proxy requests
+// as they are to the instances, which must be HTTP servers.
+//
+// The gateway is agnostic of the service discovery system; it's given a
+// function that makes subscribers, for example using package sd/consul.
+//
+//    subscribe := func(service string, factory sd.Factory) sd.Subscriber {
+//        return consulsd.NewSubscriber(client, factory, logger, service, nil, true)
+//    }
+//    g, err := gateway.New([]gateway.Route{
+//        {Prefix: "/addsvc", Service: "addsvc"},
+//        {
+//            Prefix:    "/stringsvc/uppercase",
+//            Service:   "stringsvc",
+//            Transport: gateway.HTTPTransport("GET", "/uppercase", encodeRequest, decodeResponse),
+//            Codec:     gateway.Codec{DecodeRequest: decodeRequest, EncodeResponse: encodeResponse},
+//        },
+//    }, subscribe)
+//
+package gateway
@@ -0,0 +1,250 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/guherbozdogan/kit/endpoint"
+	"github.com/guherbozdogan/kit/sd"
+	"github.com/guherbozdogan/kit/sd/lb"
+	httptransport "github.com/guherbozdogan/kit/transport/http"
+	"github.com/guherbozdogan/kit/transport/httprp"
+)
+
+const (
+	// DefaultRetryMax is the number of attempts made for a request to a route
+	// with a transport, including the first one.
+	DefaultRetryMax = 3
+
+	// DefaultRetryTimeout is the time allowed for a request to a route with a
+	// transport, including retries.
+	DefaultRetryTimeout = 500 * time.Millisecond
+)
+
+// Route maps the requests under a path prefix to a service.
+type Route struct {
+	// Prefix is the path prefix of the route, e.g. "/addsvc". Requests are
+	// matched to the route with the longest prefix, and the prefix is stripped
+	// from the path.
+	Prefix string
+
+	// Service is the name of the service in the service discovery system.
+	Service string
+
+	// Transport makes the endpoints that call the instances of the service,
+	// e.g. HTTPTransport or GRPCTransport. If nil, requests are proxied as they
+	// are to the instances.
+	Transport sd.Factory
+
+	// Codec decodes requests for, and encodes responses of, the endpoints made
+	// by the transport. It's required if the route has a transport.
+	Codec Codec
+
+	// Middleware is applied to the route's endpoint, in order. On routes
+	// without a transport, it's applied once to each request before it's
+	// proxied: the request is the *http.Request, and the context is populated
+	// by transport/http.PopulateRequestContext. If the middleware fails, the
+	// request isn't proxied, and the error is encoded by
+	// transport/http.DefaultErrorEncoder, so that errors implementing
+	// StatusCoder set the status code of the response.
+	Middleware []endpoint.Middleware
+}
+
+// Codec converts between the requests and responses of the gateway and those
+// of a route's endpoint.
+type Codec struct {
+	DecodeRequest  httptransport.DecodeRequestFunc
+	EncodeResponse httptransport.EncodeResponseFunc
+}
+
+// SubscriberFunc makes a subscriber for the instances of the named service,
+// which it converts to endpoints with the factory.
+type SubscriberFunc func(service string, factory sd.Factory) sd.Subscriber
+
+// Gateway is an http.Handler that dispatches requests to its routes.
+type Gateway struct {
+	routes        []route
+	subscribers   []sd.Subscriber
+	newBalancer   func(sd.Subscriber) lb.Balancer
+	retryMax      int
+	retryTimeout  time.Duration
+	serverOptions []httptransport.ServerOption
+	proxyOptions  []httprp.ServerOption
+}
+
+type route struct {
+	prefix  string
+	handler http.Handler
+}
+
+// GatewayOption sets an optional parameter for gateways.
+type GatewayOption func(*Gateway)
+
+// GatewayBalancer sets the balancer made for each route. By default, a round
+// robin balancer is used.
+func GatewayBalancer(newBalancer func(sd.Subscriber) lb.Balancer) GatewayOption {
+	return func(g *Gateway) { g.newBalancer = newBalancer }
+}
+
+// GatewayRetry sets the number of attempts and the timeout for requests to
+// routes with a transport. By default, DefaultRetryMax and DefaultRetryTimeout
+// are used. Routes without a transport are retried by their proxy, see
+// httprp.NewBalancedServer.
+func GatewayRetry(max int, timeout time.Duration) GatewayOption {
+	return func(g *Gateway) {
+		g.retryMax = max
+		g.retryTimeout = timeout
+	}
+}
+
+// GatewayServerOptions are passed to the servers of routes with a transport.
+func GatewayServerOptions(options ...httptransport.ServerOption) GatewayOption {
+	return func(g *Gateway) { g.serverOptions = append(g.serverOptions, options...) }
+}
+
+// GatewayProxyOptions are passed to the servers of routes without a transport.
+func GatewayProxyOptions(options ...httprp.ServerOption) GatewayOption {
+	return func(g *Gateway) { g.proxyOptions = append(g.proxyOptions, options...) }
+}
+
+// New builds a gateway for the routes, making a subscriber for each route with
+// subscribe. It returns an error if the route table is invalid.
+func New(routes []Route, subscribe SubscriberFunc, options ...GatewayOption) (*Gateway, error) {
+	g := &Gateway{
+		newBalancer:  lb.NewRoundRobin,
+		retryMax:     DefaultRetryMax,
+		retryTimeout: DefaultRetryTimeout,
+	}
+	for _, option := range options {
+		option(g)
+	}
+
+	seen := map[string]bool{}
+	for _, r := range routes {
+		prefix := strings.TrimSuffix(r.Prefix, "/")
+		if !strings.HasPrefix(r.Prefix, "/") {
+			return nil, fmt.Errorf("route %q: prefix must start with a slash", r.Prefix)
+		}
+		if seen[prefix] {
+			return nil, fmt.Errorf("route %q: duplicate prefix", r.Prefix)
+		}
+		seen[prefix] = true
+		if r.Service == "" {
+			return nil, fmt.Errorf("route %q: %v", r.Prefix, ErrServiceMissing)
+		}
+		if r.Transport != nil && (r.Codec.DecodeRequest == nil || r.Codec.EncodeResponse == nil) {
+			return nil, fmt.Errorf("route %q: %v", r.Prefix, ErrCodecMissing)
+		}
+
+		var handler http.Handler
+		if r.Transport == nil {
+			handler = g.makeProxy(r, subscribe)
+		} else {
+			handler = g.makeServer(r, subscribe)
+		}
+		g.routes = append(g.routes, route{
+			prefix:  prefix,
+			handler: http.StripPrefix(prefix, handler),
+		})
+	}
+
+	// Longest prefix first.
+	sort.Slice(g.routes, func(i, j int) bool {
+		return len(g.routes[i].prefix) > len(g.routes[j].prefix)
+	})
+	return g, nil
+}
+
+// ErrServiceMissing is returned by New for a route without a service.
+var ErrServiceMissing = errors.New("service missing")
+
+// ErrCodecMissing is returned by New for a route with a transport but without
+// a complete codec.
+var ErrCodecMissing = errors.New("codec missing")
+
+func (g *Gateway) makeServer(r Route, subscribe SubscriberFunc) http.Handler {
+	subscriber := subscribe(r.Service, r.Transport)
+	g.subscribers = append(g.subscribers, subscriber)
+
+	e := lb.Retry(g.retryMax, g.retryTimeout, g.newBalancer(subscriber))
+	e = chain(r.Middleware)(e)
+	return httptransport.NewServer(e, r.Codec.DecodeRequest, r.Codec.EncodeResponse, g.serverOptions...)
+}
+
+func (g *Gateway) makeProxy(r Route, subscribe SubscriberFunc) http.Handler {
+	subscriber := subscribe(r.Service, httprp.TargetFactory("http"))
+	g.subscribers = append(g.subscribers, subscriber)
+
+	proxy := httprp.NewBalancedServer(subscriber, g.newBalancer, g.proxyOptions...)
+	if len(r.Middleware) == 0 {
+		return proxy
+	}
+	return proxyHandler{
+		e:            chain(r.Middleware)(proxyEndpoint(proxy)),
+		errorEncoder: httptransport.DefaultErrorEncoder,
+	}
+}
+
+// ServeHTTP implements http.Handler.
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	for _, route := range g.routes {
+		if r.URL.Path == route.prefix || strings.HasPrefix(r.URL.Path, route.prefix+"/") {
+			route.handler.ServeHTTP(w, r)
+			return
+		}
+	}
+	http.NotFound(w, r)
+}
+
+// Stop stops the subscribers of the gateway that can be stopped, like those in
+// package sd/consul.
+func (g *Gateway) Stop() {
+	for _, s := range g.subscribers {
+		if s, ok := s.(interface {
+			Stop()
+		}); ok {
+			s.Stop()
+		}
+	}
+}
+
+func chain(mw []endpoint.Middleware) endpoint.Middleware {
+	if len(mw) == 0 {
+		return func(e endpoint.Endpoint) endpoint.Endpoint { return e }
+	}
+	return endpoint.Chain(mw[0], mw[1:]...)
+}
+
+type contextKey int
+
+const responseWriterKey contextKey = iota
+
+// proxyHandler calls the middleware of a route without a transport with the
+// request, and proxies it from within.
+type proxyHandler struct {
+	e            endpoint.Endpoint
+	errorEncoder httptransport.ErrorEncoder
+}
+
+func (h proxyHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := httptransport.PopulateRequestContext(r.Context(), r)
+	ctx = context.WithValue(ctx, responseWriterKey, w)
+	if _, err := h.e(ctx, r); err != nil {
+		h.errorEncoder(ctx, err, w)
+	}
+}
+
+// proxyEndpoint returns an endpoint that serves the *http.Request it's called
+// with by the proxy, writing the response to the ResponseWriter in the context.
+func proxyEndpoint(proxy http.Handler) endpoint.Endpoint {
+	return func(ctx context.Context, request interface{}) (interface{}, error) {
+		w := ctx.Value(responseWriterKey).(http.ResponseWriter)
+		proxy.ServeHTTP(w, request.(*http.Request).WithContext(ctx))
+		return nil, nil
+	}
+}
@@ -0,0 +1,199 @@
+package gateway_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/guherbozdogan/kit/endpoint"
+	"github.com/guherbozdogan/kit/gateway"
+	"github.com/guherbozdogan/kit/sd"
+	httptransport "github.com/guherbozdogan/kit/transport/http"
+)
+
+func TestGateway(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/uppercase":
+			var request struct {
+				S string `json:"s"`
+			}
+			json.NewDecoder(r.Body).Decode(&request)
+			json.NewEncoder(w).Encode(map[string]string{"v": strings.ToUpper(request.S)})
+		default:
+			w.Write([]byte("proxied " + r.URL.Path))
+		}
+	}))
+	defer upstream.Close()
+
+	var calls int
+	g, err := gateway.New([]gateway.Route{
+		{Prefix: "/stringsvc", Service: "stringsvc"},
+		{
+			Prefix:    "/stringsvc/uppercase",
+			Service:   "stringsvc",
+			Transport: gateway.HTTPTransport("POST", "/uppercase", httptransport.EncodeJSONRequest, decodeResponse),
+			Codec:     gateway.Codec{DecodeRequest: decodeRequest, EncodeResponse: httptransport.EncodeJSONResponse},
+			Middleware: []endpoint.Middleware{func(next endpoint.Endpoint) endpoint.Endpoint {
+				return func(ctx context.Context, request interface{}) (interface{}, error) {
+					calls++
+					return next(ctx, request)
+				}
+			}},
+		},
+	}, fixedSubscribe(map[string][]string{"stringsvc": {strings.TrimPrefix(upstream.URL, "http://")}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer g.Stop()
+	server := httptest.NewServer(g)
+	defer server.Close()
+
+	resp, err := http.Post(server.URL+"/stringsvc/uppercase", "application/json", strings.NewReader(`{"s":"hey"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	if want, have := `{"v":"HEY"}`, strings.TrimSpace(string(body)); want != have {
+		t.Errorf("want %s, have %s", want, have)
+	}
+	if want, have := 1, calls; want != have {
+		t.Errorf("want %d, have %d", want, have)
+	}
+
+	resp, err = http.Get(server.URL + "/stringsvc/count")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ = ioutil.ReadAll(resp.Body)
+	if want, have := "proxied /count", string(body); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+
+	resp, err = http.Get(server.URL + "/stringsvcs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, have := http.StatusNotFound, resp.StatusCode; want != have {
+		t.Errorf("want %d, have %d", want, have)
+	}
+}
+
+func TestGatewayProxyMiddleware(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("proxied " + r.URL.Path))
+	}))
+	defer upstream.Close()
+
+	var paths []string
+	g, err := gateway.New([]gateway.Route{{
+		Prefix:  "/stringsvc",
+		Service: "stringsvc",
+		Middleware: []endpoint.Middleware{func(next endpoint.Endpoint) endpoint.Endpoint {
+			return func(ctx context.Context, request interface{}) (interface{}, error) {
+				paths = append(paths, request.(*http.Request).URL.Path)
+				if ctx.Value(httptransport.ContextKeyRequestAuthorization) != "secret" {
+					return nil, unauthorizedError{}
+				}
+				return next(ctx, request)
+			}
+		}},
+	}}, fixedSubscribe(map[string][]string{"stringsvc": {strings.TrimPrefix(upstream.URL, "http://")}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer g.Stop()
+	server := httptest.NewServer(g)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/stringsvc/count")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, have := http.StatusUnauthorized, resp.StatusCode; want != have {
+		t.Errorf("want %d, have %d", want, have)
+	}
+
+	req, _ := http.NewRequest("GET", server.URL+"/stringsvc/count", nil)
+	req.Header.Set("Authorization", "secret")
+	resp, err = http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	if want, have := "proxied /count", string(body); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+
+	// The middleware runs once per request.
+	if want, have := []string{"/count", "/count"}, paths; fmt.Sprint(want) != fmt.Sprint(have) {
+		t.Errorf("want %v, have %v", want, have)
+	}
+}
+
+type unauthorizedError struct{}
+
+func (unauthorizedError) Error() string   { return "unauthorized" }
+func (unauthorizedError) StatusCode() int { return http.StatusUnauthorized }
+
+func TestGatewayInvalidRoutes(t *testing.T) {
+	subscribe := fixedSubscribe(nil)
+	transport := gateway.HTTPTransport("GET", "/", nil, nil)
+	for _, tc := range []struct {
+		name   string
+		routes []gateway.Route
+	}{
+		{"relative prefix", []gateway.Route{{Prefix: "a", Service: "a"}}},
+		{"duplicate prefix", []gateway.Route{{Prefix: "/a", Service: "a"}, {Prefix: "/a/", Service: "b"}}},
+		{"missing service", []gateway.Route{{Prefix: "/a"}}},
+		{"missing codec", []gateway.Route{{Prefix: "/a", Service: "a", Transport: transport}}},
+	} {
+		if _, err := gateway.New(tc.routes, subscribe); err == nil {
+			t.Errorf("%s: want error, have none", tc.name)
+		}
+	}
+}
+
+// fixedSubscribe makes subscribers for a fixed set of instances per service.
+func fixedSubscribe(instances map[string][]string) gateway.SubscriberFunc {
+	return func(service string, factory sd.Factory) sd.Subscriber {
+		var endpoints sd.FixedSubscriber
+		for _, instance := range instances[service] {
+			e, _, err := factory(instance)
+			if err != nil {
+				panic(err)
+			}
+			endpoints = append(endpoints, e)
+		}
+		return endpoints
+	}
+}
+
+func decodeRequest(_ context.Context, r *http.Request) (interface{}, error) {
+	var request struct {
+		S string `json:"s"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+func decodeResponse(_ context.Context, resp *http.Response) (interface{}, error) {
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.New(resp.Status)
+	}
+	var response struct {
+		V string `json:"v"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
@@ -0,0 +1,56 @@
+package gateway
+
+import (
+	"io"
+	"net/url"
+	"strings"
+
+	"google.golang.org/grpc"
+
+	"github.com/guherbozdogan/kit/endpoint"
+	"github.com/guherbozdogan/kit/sd"
+	grpctransport "github.com/guherbozdogan/kit/transport/grpc"
+	httptransport "github.com/guherbozdogan/kit/transport/http"
+)
+
+// HTTPTransport returns a transport that calls the method and path on each
+// instance, which is a host:port or a URL.
+func HTTPTransport(
+	method, path string,
+	enc httptransport.EncodeRequestFunc,
+	dec httptransport.DecodeResponseFunc,
+	options ...httptransport.ClientOption,
+) sd.Factory {
+	return func(instance string) (endpoint.Endpoint, io.Closer, error) {
+		if !strings.Contains(instance, "://") {
+			instance = "http://" + instance
+		}
+		tgt, err := url.Parse(instance)
+		if err != nil {
+			return nil, nil, err
+		}
+		tgt.Path = path
+		return httptransport.NewClient(method, tgt, enc, dec, options...).Endpoint(), nil, nil
+	}
+}
+
+// GRPCTransport returns a transport that dials each instance with the dial
+// options, and calls the method of the service on it. The connection is closed
+// when the instance goes away.
+func GRPCTransport(
+	dialOptions []grpc.DialOption,
+	serviceName, method string,
+	enc grpctransport.EncodeRequestFunc,
+	dec grpctransport.DecodeResponseFunc,
+	grpcReply interface{},
+	options ...grpctransport.ClientOption,
+) sd.Factory {
+	return func(instance string) (endpoint.Endpoint, io.Closer, error) {
+		conn, err := grpc.Dial(instance, dialOptions...)
+		if err != nil {
+			return nil, nil, err
+		}
+		client := grpctransport.NewClient(conn, serviceName, method, enc, dec, grpcReply, options...)
+		return client.Endpoint(), conn, nil
+	}
+}
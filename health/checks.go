@@ -0,0 +1,39 @@
+package health
+
+import (
+	"context"
+	"errors"
+
+	"github.com/guherbozdogan/kit/sd"
+	"github.com/guherbozdogan/kit/util/conn"
+)
+
+// ErrNoEndpoints is reported by SubscriberCheck when the subscriber yields no
+// endpoints.
+var ErrNoEndpoints = errors.New("no endpoints available")
+
+// SubscriberCheck returns a check which passes if the subscriber yields at
+// least one endpoint.
+func SubscriberCheck(s sd.Subscriber) CheckFunc {
+	return func(context.Context) error {
+		endpoints, err := s.Endpoints()
+		if err != nil {
+			return err
+		}
+		if len(endpoints) == 0 {
+			return ErrNoEndpoints
+		}
+		return nil
+	}
+}
+
+// ConnCheck returns a check which passes if the connection manager currently
+// holds a connection.
+func ConnCheck(m *conn.Manager) CheckFunc {
+	return func(context.Context) error {
+		if m.Take() == nil {
+			return conn.ErrConnectionUnavailable
+		}
+		return nil
+	}
+}
@@ -0,0 +1,6 @@
+// Package health provides liveness and readiness reporting for services. Checks
+// are registered by name in a Registry, and evaluated concurrently, each with
+// its own timeout, whenever a probe asks for a report. The result is exposed
+// as an HTTP handler built on package transport/http, and as an implementation
+// of the standard gRPC health checking service.
+package health
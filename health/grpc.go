@@ -0,0 +1,90 @@
+package health
+
+import (
+	"time"
+
+	oldcontext "golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// DefaultWatchInterval is how often GRPCServer re-evaluates checks for Watch
+// streams, unless configured otherwise.
+const DefaultWatchInterval = 5 * time.Second
+
+// GRPCServer implements the standard gRPC health checking service on top of a
+// Registry. The empty service name refers to the readiness of the whole
+// server; any other service name refers to the check of the same name.
+type GRPCServer struct {
+	// Embedded so that methods added to the health service by newer grpc
+	// versions are implemented, returning Unimplemented.
+	healthpb.UnimplementedHealthServer
+
+	registry      *Registry
+	watchInterval time.Duration
+}
+
+var _ healthpb.HealthServer = &GRPCServer{}
+
+// NewGRPCServer returns a gRPC health server backed by the registry. Register
+// it with healthpb.RegisterHealthServer.
+func NewGRPCServer(r *Registry) *GRPCServer {
+	return &GRPCServer{
+		registry:      r,
+		watchInterval: DefaultWatchInterval,
+	}
+}
+
+// Check implements the gRPC health service.
+func (s *GRPCServer) Check(ctx oldcontext.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	status, ok := s.status(ctx, req.Service)
+	if !ok {
+		return nil, grpc.Errorf(codes.NotFound, "unknown service %q", req.Service)
+	}
+	return &healthpb.HealthCheckResponse{Status: status}, nil
+}
+
+// Watch implements the gRPC health service. Checks are re-evaluated on a fixed
+// schedule, and the status is sent whenever it changes.
+func (s *GRPCServer) Watch(req *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer) error {
+	ctx := stream.Context()
+	ticker := time.NewTicker(s.watchInterval)
+	defer ticker.Stop()
+
+	var last healthpb.HealthCheckResponse_ServingStatus = -1
+	for {
+		status, ok := s.status(ctx, req.Service)
+		if !ok {
+			status = healthpb.HealthCheckResponse_SERVICE_UNKNOWN
+		}
+		if status != last {
+			if err := stream.Send(&healthpb.HealthCheckResponse{Status: status}); err != nil {
+				return err
+			}
+			last = status
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (s *GRPCServer) status(ctx oldcontext.Context, service string) (healthpb.HealthCheckResponse_ServingStatus, bool) {
+	var report Report
+	if service == "" {
+		report = s.registry.Ready(ctx)
+	} else {
+		var ok bool
+		if report, ok = s.registry.Check(ctx, service); !ok {
+			return healthpb.HealthCheckResponse_UNKNOWN, false
+		}
+	}
+	if report.Status != StatusPass {
+		return healthpb.HealthCheckResponse_NOT_SERVING, true
+	}
+	return healthpb.HealthCheckResponse_SERVING, true
+}
@@ -0,0 +1,38 @@
+package health
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestGRPCServerCheck(t *testing.T) {
+	r := NewRegistry()
+	r.Register(Check{Name: "loop", Func: pass, Critical: true})
+	r.Register(Check{Name: "db", Func: fail, Critical: true})
+	r.Register(Check{Name: "cache", Func: fail})
+	s := NewGRPCServer(r)
+
+	for service, want := range map[string]healthpb.HealthCheckResponse_ServingStatus{
+		"":      healthpb.HealthCheckResponse_NOT_SERVING,
+		"loop":  healthpb.HealthCheckResponse_SERVING,
+		"db":    healthpb.HealthCheckResponse_NOT_SERVING,
+		"cache": healthpb.HealthCheckResponse_NOT_SERVING, // not critical, but failing
+	} {
+		resp, err := s.Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+		if err != nil {
+			t.Fatalf("%q: %v", service, err)
+		}
+		if have := resp.Status; want != have {
+			t.Errorf("%q: want %s, have %s", service, want, have)
+		}
+	}
+
+	_, err := s.Check(context.Background(), &healthpb.HealthCheckRequest{Service: "nonexistent"})
+	if want, have := codes.NotFound, grpc.Code(err); want != have {
+		t.Errorf("want %s, have %s", want, have)
+	}
+}
@@ -0,0 +1,184 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// DefaultTimeout is the timeout of checks that don't specify their own.
+const DefaultTimeout = 5 * time.Second
+
+// CheckFunc reports the health of a dependency or subsystem. It returns nil if
+// the dependency is healthy. It should respect the deadline of the context.
+type CheckFunc func(ctx context.Context) error
+
+// Check is a named health check.
+type Check struct {
+	Name    string
+	Func    CheckFunc
+	Timeout time.Duration // DefaultTimeout if zero
+
+	// Critical checks fail the report when they fail. Failures of other checks
+	// are reported, but don't affect the overall status.
+	Critical bool
+
+	// Liveness checks are evaluated by both the liveness and the readiness
+	// probe; all other checks only by the readiness probe. Only checks whose
+	// failure can be fixed by restarting the process should be liveness checks.
+	Liveness bool
+}
+
+// Status is the outcome of a check, or of a report.
+type Status string
+
+// Statuses.
+const (
+	StatusPass Status = "pass"
+	StatusFail Status = "fail"
+)
+
+// Result is the outcome of a single check.
+type Result struct {
+	Name     string `json:"name"`
+	Status   Status `json:"status"`
+	Critical bool   `json:"critical"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+// Report is the aggregate outcome of a set of checks. It fails if any of its
+// critical checks failed. Report implements transport/http.StatusCoder.
+type Report struct {
+	Status Status   `json:"status"`
+	Checks []Result `json:"checks"`
+}
+
+// StatusCode returns 200 for passing reports, and 503 otherwise.
+func (r Report) StatusCode() int {
+	if r.Status != StatusPass {
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusOK
+}
+
+// ErrTimeout is reported for checks which don't return within their timeout.
+var ErrTimeout = errors.New("check timed out")
+
+// Registry holds a set of checks.
+type Registry struct {
+	mtx    sync.RWMutex
+	checks []Check
+}
+
+// NewRegistry returns an empty Registry. An empty registry always passes.
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+// Register adds a check to the registry. Check names should be unique.
+func (r *Registry) Register(c Check) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.checks = append(r.checks, c)
+}
+
+// Live evaluates the liveness checks.
+func (r *Registry) Live(ctx context.Context) Report {
+	return run(ctx, r.filter(func(c Check) bool { return c.Liveness }))
+}
+
+// Ready evaluates all checks.
+func (r *Registry) Ready(ctx context.Context) Report {
+	return run(ctx, r.filter(func(Check) bool { return true }))
+}
+
+// Check evaluates the named check. Unlike the other reports, it fails if the
+// check fails, whether it's critical or not. It returns false if there's no
+// such check.
+func (r *Registry) Check(ctx context.Context, name string) (Report, bool) {
+	checks := r.filter(func(c Check) bool { return c.Name == name })
+	if len(checks) == 0 {
+		return Report{}, false
+	}
+	report := run(ctx, checks)
+	for _, res := range report.Checks {
+		if res.Status != StatusPass {
+			report.Status = StatusFail
+		}
+	}
+	return report, true
+}
+
+func (r *Registry) filter(f func(Check) bool) []Check {
+	r.mtx.RLock()
+	defer r.mtx.RUnlock()
+	var checks []Check
+	for _, c := range r.checks {
+		if f(c) {
+			checks = append(checks, c)
+		}
+	}
+	return checks
+}
+
+// run evaluates checks concurrently, and aggregates their results in the order
+// the checks were given.
+func run(ctx context.Context, checks []Check) Report {
+	var (
+		results = make([]Result, len(checks))
+		wg      sync.WaitGroup
+	)
+	for i, c := range checks {
+		wg.Add(1)
+		go func(i int, c Check) {
+			defer wg.Done()
+			results[i] = evaluate(ctx, c)
+		}(i, c)
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusPass, Checks: results}
+	for _, res := range results {
+		if res.Critical && res.Status != StatusPass {
+			report.Status = StatusFail
+		}
+	}
+	return report
+}
+
+func evaluate(ctx context.Context, c Check) Result {
+	timeout := c.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var (
+		begin = time.Now()
+		errc  = make(chan error, 1)
+	)
+	go func() { errc <- c.Func(ctx) }()
+
+	var err error
+	select {
+	case err = <-errc:
+	case <-ctx.Done():
+		err = ErrTimeout
+	}
+
+	res := Result{
+		Name:     c.Name,
+		Status:   StatusPass,
+		Critical: c.Critical,
+		Duration: time.Since(begin).String(),
+	}
+	if err != nil {
+		res.Status = StatusFail
+		res.Error = err.Error()
+	}
+	return res
+}
@@ -0,0 +1,99 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRegistryEmpty(t *testing.T) {
+	r := NewRegistry()
+	if want, have := StatusPass, r.Live(context.Background()).Status; want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+	if want, have := StatusPass, r.Ready(context.Background()).Status; want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+}
+
+func TestRegistryLivenessReadiness(t *testing.T) {
+	r := NewRegistry()
+	r.Register(Check{Name: "loop", Func: pass, Critical: true, Liveness: true})
+	r.Register(Check{Name: "db", Func: fail, Critical: true})
+
+	live := r.Live(context.Background())
+	if want, have := StatusPass, live.Status; want != have {
+		t.Errorf("live: want %q, have %q", want, have)
+	}
+	if want, have := 1, len(live.Checks); want != have {
+		t.Fatalf("live: want %d, have %d", want, have)
+	}
+
+	ready := r.Ready(context.Background())
+	if want, have := StatusFail, ready.Status; want != have {
+		t.Errorf("ready: want %q, have %q", want, have)
+	}
+	if want, have := 2, len(ready.Checks); want != have {
+		t.Fatalf("ready: want %d, have %d", want, have)
+	}
+	if want, have := "db", ready.Checks[1].Name; want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+	if want, have := errFail.Error(), ready.Checks[1].Error; want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+}
+
+func TestRegistryNonCritical(t *testing.T) {
+	r := NewRegistry()
+	r.Register(Check{Name: "cache", Func: fail})
+
+	report := r.Ready(context.Background())
+	if want, have := StatusPass, report.Status; want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+	if want, have := StatusFail, report.Checks[0].Status; want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+}
+
+func TestRegistryTimeout(t *testing.T) {
+	r := NewRegistry()
+	r.Register(Check{
+		Name:     "slow",
+		Func:     func(context.Context) error { time.Sleep(time.Second); return nil },
+		Timeout:  10 * time.Millisecond,
+		Critical: true,
+	})
+
+	begin := time.Now()
+	report := r.Ready(context.Background())
+	if took := time.Since(begin); took > 500*time.Millisecond {
+		t.Errorf("took %s", took)
+	}
+	if want, have := ErrTimeout.Error(), report.Checks[0].Error; want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+}
+
+func TestRegistryCheck(t *testing.T) {
+	r := NewRegistry()
+	r.Register(Check{Name: "db", Func: fail, Critical: true})
+
+	if _, ok := r.Check(context.Background(), "nonexistent"); ok {
+		t.Error("want false, have true")
+	}
+	report, ok := r.Check(context.Background(), "db")
+	if !ok {
+		t.Fatal("want true, have false")
+	}
+	if want, have := StatusFail, report.Status; want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+}
+
+var errFail = errors.New("fail")
+
+func pass(context.Context) error { return nil }
+func fail(context.Context) error { return errFail }
@@ -0,0 +1,38 @@
+package health
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/guherbozdogan/kit/endpoint"
+	httptransport "github.com/guherbozdogan/kit/transport/http"
+)
+
+// MakeLivenessEndpoint returns an endpoint which yields the liveness Report of
+// the registry. The request is ignored.
+func MakeLivenessEndpoint(r *Registry) endpoint.Endpoint {
+	return func(ctx context.Context, _ interface{}) (interface{}, error) {
+		return r.Live(ctx), nil
+	}
+}
+
+// MakeReadinessEndpoint returns an endpoint which yields the readiness Report
+// of the registry. The request is ignored.
+func MakeReadinessEndpoint(r *Registry) endpoint.Endpoint {
+	return func(ctx context.Context, _ interface{}) (interface{}, error) {
+		return r.Ready(ctx), nil
+	}
+}
+
+// NewHTTPHandler returns an HTTP handler which serves the Report yielded by the
+// endpoint as JSON, with status 200 if it passes and 503 otherwise. Mount it
+// e.g. on /healthz with MakeLivenessEndpoint, and on /readyz with
+// MakeReadinessEndpoint.
+func NewHTTPHandler(e endpoint.Endpoint, options ...httptransport.ServerOption) http.Handler {
+	return httptransport.NewServer(
+		e,
+		func(context.Context, *http.Request) (interface{}, error) { return nil, nil },
+		httptransport.EncodeJSONResponse,
+		options...,
+	)
+}
@@ -0,0 +1,42 @@
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHTTPHandler(t *testing.T) {
+	r := NewRegistry()
+	r.Register(Check{Name: "loop", Func: pass, Critical: true, Liveness: true})
+	r.Register(Check{Name: "db", Func: fail, Critical: true})
+
+	for _, tc := range []struct {
+		handler http.Handler
+		code    int
+		status  Status
+	}{
+		{NewHTTPHandler(MakeLivenessEndpoint(r)), http.StatusOK, StatusPass},
+		{NewHTTPHandler(MakeReadinessEndpoint(r)), http.StatusServiceUnavailable, StatusFail},
+	} {
+		server := httptest.NewServer(tc.handler)
+		resp, err := http.Get(server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var report Report
+		err = json.NewDecoder(resp.Body).Decode(&report)
+		resp.Body.Close()
+		server.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want, have := tc.code, resp.StatusCode; want != have {
+			t.Errorf("want %d, have %d", want, have)
+		}
+		if want, have := tc.status, report.Status; want != have {
+			t.Errorf("want %q, have %q", want, have)
+		}
+	}
+}
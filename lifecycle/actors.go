@@ -0,0 +1,156 @@
+package lifecycle
+
+import (
+	"context"
+	"net"
+	"net/http"
+
+	"github.com/guherbozdogan/kit/sd"
+)
+
+// HTTPServer returns an actor which serves HTTP on the listener. Stopping it
+// shuts the server down gracefully, waiting for in-flight requests until the
+// context is done.
+func HTTPServer(name string, srv *http.Server, ln net.Listener) Actor {
+	return Actor{
+		Name: name,
+		Start: func(fail func(error)) error {
+			go func() {
+				if err := srv.Serve(ln); err != http.ErrServerClosed {
+					fail(err)
+				}
+			}()
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			return srv.Shutdown(ctx)
+		},
+	}
+}
+
+// GracefulServer is the subset of *grpc.Server used by the GRPCServer actor.
+type GracefulServer interface {
+	Serve(net.Listener) error
+	GracefulStop()
+	Stop()
+}
+
+// GRPCServer returns an actor which serves gRPC on the listener. Stopping it
+// stops the server gracefully, waiting for in-flight RPCs until the context is
+// done, after which the server is stopped forcibly.
+func GRPCServer(name string, srv GracefulServer, ln net.Listener) Actor {
+	return Actor{
+		Name: name,
+		Start: func(fail func(error)) error {
+			go func() {
+				if err := srv.Serve(ln); err != nil {
+					fail(err)
+				}
+			}()
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			done := make(chan struct{})
+			go func() {
+				srv.GracefulStop()
+				close(done)
+			}()
+			select {
+			case <-done:
+				return nil
+			case <-ctx.Done():
+				srv.Stop()
+				<-done
+				return ctx.Err()
+			}
+		},
+	}
+}
+
+// Registrar returns an actor which registers the service on start, and
+// deregisters it as the first step of a shutdown.
+func Registrar(name string, r sd.Registrar) Actor {
+	return Actor{
+		Name: name,
+		Start: func(func(error)) error {
+			r.Register()
+			return nil
+		},
+		Stop: func(context.Context) error {
+			r.Deregister()
+			return nil
+		},
+		Deregister: true,
+	}
+}
+
+// Stopper is implemented by components which run from construction until
+// they're stopped, such as most sd.Subscriber implementations.
+type Stopper interface {
+	Stop()
+}
+
+// Stop returns an actor which stops the given component on shutdown.
+func Stop(name string, s Stopper) Actor {
+	return Actor{
+		Name: name,
+		Stop: func(context.Context) error {
+			s.Stop()
+			return nil
+		},
+	}
+}
+
+// Loop returns an actor which invokes run in its own goroutine, e.g. to report
+// metrics periodically. Run should return when its context is canceled, which
+// happens when the actor is stopped. If run returns before that, the manager is
+// shut down.
+func Loop(name string, run func(ctx context.Context) error) Actor {
+	var (
+		cancel context.CancelFunc
+		done   = make(chan struct{})
+	)
+	return Actor{
+		Name: name,
+		Start: func(fail func(error)) error {
+			var ctx context.Context
+			ctx, cancel = context.WithCancel(context.Background())
+			go func() {
+				defer close(done)
+				err := run(ctx)
+				if ctx.Err() == nil {
+					fail(err)
+				}
+			}()
+			return nil
+		},
+		Stop: func(ctx context.Context) error {
+			cancel()
+			select {
+			case <-done:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+	}
+}
+
+// Drainer is implemented by the servers in package transport, which can reject
+// new requests and wait for in-flight ones to finish.
+type Drainer interface {
+	Drain()
+	Wait(ctx context.Context) error
+}
+
+// Drain returns an actor which drains the given server on shutdown. Add it
+// after the server it drains, so that it's stopped first.
+func Drain(name string, d Drainer) Actor {
+	return Actor{
+		Name: name,
+		Stop: func(ctx context.Context) error {
+			d.Drain()
+			return d.Wait(ctx)
+		},
+	}
+}
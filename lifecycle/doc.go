@@ -0,0 +1,7 @@
+// Package lifecycle runs the long-lived components of a service, such as
+// servers, service discovery registrars and subscribers, and background loops.
+// Components are started in the order they're added. On shutdown, triggered
+// by a signal or by a component failing, the service first deregisters from
+// service discovery, and then stops the remaining components in reverse order,
+// giving them a bounded amount of time to drain in-flight work.
+package lifecycle
@@ -0,0 +1,180 @@
+package lifecycle
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/guherbozdogan/kit/log"
+)
+
+// DefaultDrainTimeout is the default time given to actors to stop.
+const DefaultDrainTimeout = 10 * time.Second
+
+// Actor is a named component managed by a Manager. Start must return once the
+// actor is started; actors that run in the background report failures via the
+// fail func passed to Start, which triggers a shutdown of the whole Manager.
+// Stop must return once the actor is stopped, or when the context is done.
+// Either func may be nil.
+type Actor struct {
+	Name  string
+	Start func(fail func(error)) error
+	Stop  func(ctx context.Context) error
+
+	// Deregister marks actors which announce the service to others, such as
+	// sd.Registrars. They're stopped before all other actors, so that clients
+	// stop sending new requests while in-flight requests are drained.
+	Deregister bool
+}
+
+// Manager starts and stops a group of actors.
+type Manager struct {
+	actors       []Actor
+	logger       log.Logger
+	drainTimeout time.Duration
+	signals      []os.Signal
+}
+
+// Option sets an optional parameter for managers.
+type Option func(*Manager)
+
+// DrainTimeout sets the time actors are given to stop, collectively, once the
+// service is deregistered. By default, DefaultDrainTimeout is used.
+func DrainTimeout(d time.Duration) Option {
+	return func(m *Manager) { m.drainTimeout = d }
+}
+
+// Signals sets the signals which trigger a shutdown. By default, SIGINT and
+// SIGTERM are used. Passing no signals disables signal handling.
+func Signals(sig ...os.Signal) Option {
+	return func(m *Manager) { m.signals = sig }
+}
+
+// NewManager returns a Manager without any actors.
+func NewManager(logger log.Logger, options ...Option) *Manager {
+	m := &Manager{
+		logger:       logger,
+		drainTimeout: DefaultDrainTimeout,
+		signals:      []os.Signal{os.Interrupt, syscall.SIGTERM},
+	}
+	for _, option := range options {
+		option(m)
+	}
+	return m
+}
+
+// Add adds an actor to the manager. It must be called before Run.
+func (m *Manager) Add(a Actor) {
+	m.actors = append(m.actors, a)
+}
+
+// Run starts all actors in order, and blocks until a signal is received, the
+// context is canceled, or an actor fails. It then stops all started actors,
+// and returns the error that caused the shutdown, if any, combined with any
+// errors returned while stopping.
+func (m *Manager) Run(ctx context.Context) error {
+	failc := make(chan error, len(m.actors))
+
+	var (
+		started []Actor
+		cause   error
+	)
+	for _, a := range m.actors {
+		if err := start(a, failc); err != nil {
+			m.logger.Log("actor", a.Name, "during", "start", "err", err)
+			cause = fmt.Errorf("%s: %v", a.Name, err)
+			break
+		}
+		m.logger.Log("actor", a.Name, "action", "start")
+		started = append(started, a)
+	}
+
+	if cause == nil {
+		sigc := make(chan os.Signal, 1)
+		if len(m.signals) > 0 {
+			signal.Notify(sigc, m.signals...)
+			defer signal.Stop(sigc)
+		}
+
+		select {
+		case sig := <-sigc:
+			m.logger.Log("signal", sig)
+		case <-ctx.Done():
+			m.logger.Log("err", ctx.Err())
+		case cause = <-failc:
+			m.logger.Log("err", cause)
+		}
+	}
+
+	errs := m.stop(started)
+	if cause != nil {
+		errs = append(Errors{cause}, errs...)
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// stop deregisters actors marked as such, and then stops the remaining actors
+// in reverse order.
+func (m *Manager) stop(actors []Actor) Errors {
+	var errs Errors
+	stop := func(ctx context.Context, a Actor) {
+		if a.Stop == nil {
+			return
+		}
+		if err := a.Stop(ctx); err != nil {
+			m.logger.Log("actor", a.Name, "during", "stop", "err", err)
+			errs = append(errs, fmt.Errorf("%s: %v", a.Name, err))
+			return
+		}
+		m.logger.Log("actor", a.Name, "action", "stop")
+	}
+
+	for i := len(actors) - 1; i >= 0; i-- {
+		if actors[i].Deregister {
+			stop(context.Background(), actors[i])
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), m.drainTimeout)
+	defer cancel()
+	for i := len(actors) - 1; i >= 0; i-- {
+		if !actors[i].Deregister {
+			stop(ctx, actors[i])
+		}
+	}
+	return errs
+}
+
+func start(a Actor, failc chan<- error) error {
+	if a.Start == nil {
+		return nil
+	}
+	return a.Start(func(err error) {
+		if err == nil {
+			err = fmt.Errorf("stopped unexpectedly")
+		}
+		select {
+		case failc <- fmt.Errorf("%s: %v", a.Name, err):
+		default: // a shutdown is already under way
+		}
+	})
+}
+
+// Errors collects the errors that occurred while running and stopping actors.
+type Errors []error
+
+// Error implements the error interface.
+func (e Errors) Error() string {
+	s := make([]string, len(e))
+	for i, err := range e {
+		s[i] = err.Error()
+	}
+	return strings.Join(s, "; ")
+}
@@ -0,0 +1,180 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/guherbozdogan/kit/log"
+)
+
+type recorder struct {
+	mtx    sync.Mutex
+	events []string
+}
+
+func (r *recorder) record(event string) {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *recorder) actor(name string, deregister bool) Actor {
+	return Actor{
+		Name:       name,
+		Start:      func(func(error)) error { r.record("start " + name); return nil },
+		Stop:       func(context.Context) error { r.record("stop " + name); return nil },
+		Deregister: deregister,
+	}
+}
+
+func TestOrder(t *testing.T) {
+	r := &recorder{}
+	m := NewManager(log.NewNopLogger(), Signals())
+	m.Add(r.actor("subscriber", false))
+	m.Add(r.actor("server", false))
+	m.Add(r.actor("registrar", true))
+	m.Add(r.actor("metrics", false))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := m.Run(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{
+		"start subscriber",
+		"start server",
+		"start registrar",
+		"start metrics",
+		"stop registrar",
+		"stop metrics",
+		"stop server",
+		"stop subscriber",
+	}
+	if have := r.events; !reflect.DeepEqual(want, have) {
+		t.Errorf("want %v, have %v", want, have)
+	}
+}
+
+func TestStartFailure(t *testing.T) {
+	r := &recorder{}
+	m := NewManager(log.NewNopLogger(), Signals())
+	m.Add(r.actor("first", false))
+	m.Add(Actor{
+		Name:  "second",
+		Start: func(func(error)) error { return errors.New("kaboom") },
+		Stop:  func(context.Context) error { r.record("stop second"); return nil },
+	})
+	m.Add(r.actor("third", false))
+
+	err := m.Run(context.Background())
+	if want, have := "second: kaboom", errString(err); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+	if want, have := []string{"start first", "stop first"}, r.events; !reflect.DeepEqual(want, have) {
+		t.Errorf("want %v, have %v", want, have)
+	}
+}
+
+func TestActorFailure(t *testing.T) {
+	r := &recorder{}
+	m := NewManager(log.NewNopLogger(), Signals())
+	m.Add(r.actor("first", false))
+	m.Add(Loop("loop", func(context.Context) error { return errors.New("kaboom") }))
+
+	err := m.Run(context.Background())
+	if want, have := "loop: kaboom", errString(err); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+	if want, have := []string{"start first", "stop first"}, r.events; !reflect.DeepEqual(want, have) {
+		t.Errorf("want %v, have %v", want, have)
+	}
+}
+
+func TestStopErrors(t *testing.T) {
+	m := NewManager(log.NewNopLogger(), Signals())
+	m.Add(Actor{Name: "a", Stop: func(context.Context) error { return errors.New("one") }})
+	m.Add(Actor{Name: "b", Stop: func(context.Context) error { return errors.New("two") }})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := m.Run(ctx)
+	if want, have := "b: two; a: one", errString(err); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+}
+
+func TestDrainTimeout(t *testing.T) {
+	m := NewManager(log.NewNopLogger(), Signals(), DrainTimeout(10*time.Millisecond))
+	m.Add(Loop("stubborn", func(context.Context) error {
+		select {} // never returns
+	}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := m.Run(ctx)
+	if want, have := "stubborn: "+context.DeadlineExceeded.Error(), errString(err); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+}
+
+func TestHTTPServer(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		started  = make(chan struct{})
+		finished = make(chan struct{})
+	)
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-finished
+		w.Write([]byte("ok"))
+	})}
+
+	m := NewManager(log.NewNopLogger(), Signals())
+	m.Add(HTTPServer("http", srv, ln))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errc := make(chan error, 1)
+	go func() { errc <- m.Run(ctx) }()
+
+	respc := make(chan string, 1)
+	go func() {
+		resp, err := http.Get("http://" + ln.Addr().String())
+		if err != nil {
+			respc <- err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		respc <- resp.Status
+	}()
+
+	// Shut down while a request is in flight; it must still complete.
+	<-started
+	cancel()
+	time.Sleep(10 * time.Millisecond)
+	close(finished)
+
+	if want, have := "200 OK", <-respc; want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+	if err := <-errc; err != nil {
+		t.Error(err)
+	}
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -0,0 +1,154 @@
+package log
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrAsyncLoggerClosed is returned by AsyncLogger.Log after Close.
+var ErrAsyncLoggerClosed = errors.New("async logger closed")
+
+// AsyncLogger is a logger that passes log events to the wrapped logger in a
+// background goroutine, so that callers don't wait for slow writers. Events
+// are queued in a bounded buffer; when it's full, Log either blocks until
+// there's room, which is the default, or drops the event.
+//
+// Valuers in a contextual logger wrapped by an AsyncLogger are bound when Log
+// is called, not when the event is written, so timestamps and callers are
+// those of the call site. Valuers passed to Log directly are left to the
+// wrapped logger, as with any other logger.
+//
+// Applications should call Close before exiting to write the queued events.
+type AsyncLogger struct {
+	logger    Logger
+	keyvals   []interface{}
+	hasValuer bool
+
+	drop         bool
+	errorHandler func(error)
+	dropped      uint64 // accessed atomically
+
+	mtx    sync.RWMutex
+	closed bool
+	queue  chan asyncEvent
+	done   chan struct{}
+}
+
+type asyncEvent struct {
+	keyvals []interface{}
+	flushed chan struct{}
+}
+
+// AsyncOption sets an optional parameter for async loggers.
+type AsyncOption func(*AsyncLogger)
+
+// AsyncDrop makes Log drop events when the buffer is full, rather than block.
+// Dropped events are counted, see AsyncLogger.Dropped.
+func AsyncDrop() AsyncOption {
+	return func(l *AsyncLogger) { l.drop = true }
+}
+
+// AsyncErrorHandler sets a function that is called with the errors returned
+// by the wrapped logger. By default, errors are discarded.
+func AsyncErrorHandler(f func(error)) AsyncOption {
+	return func(l *AsyncLogger) { l.errorHandler = f }
+}
+
+// NewAsyncLogger returns an async logger that queues up to size events for
+// next, and starts its background goroutine.
+func NewAsyncLogger(next Logger, size int, options ...AsyncOption) *AsyncLogger {
+	c := newContext(next)
+	l := &AsyncLogger{
+		logger:       c.logger,
+		keyvals:      c.keyvals,
+		hasValuer:    c.hasValuer,
+		errorHandler: func(error) {},
+		queue:        make(chan asyncEvent, size),
+		done:         make(chan struct{}),
+	}
+	for _, option := range options {
+		option(l)
+	}
+	go l.loop()
+	return l
+}
+
+// Log implements Logger. It returns ErrAsyncLoggerClosed after Close, and no
+// error for events that are dropped.
+func (l *AsyncLogger) Log(keyvals ...interface{}) error {
+	// The queued event must not share its backing array with keyvals or
+	// the context, see the Logger interface contract.
+	kvs := make([]interface{}, 0, len(l.keyvals)+len(keyvals)+1)
+	kvs = append(kvs, l.keyvals...)
+	kvs = append(kvs, keyvals...)
+	if len(kvs)%2 != 0 {
+		kvs = append(kvs, ErrMissingValue)
+	}
+	if l.hasValuer {
+		// Called directly from Log to keep the stack depth of a context, so
+		// that Caller valuers resolve the call site.
+		bindValues(kvs[:len(l.keyvals)])
+	}
+
+	l.mtx.RLock()
+	defer l.mtx.RUnlock()
+	if l.closed {
+		return ErrAsyncLoggerClosed
+	}
+	if !l.drop {
+		l.queue <- asyncEvent{keyvals: kvs}
+		return nil
+	}
+	select {
+	case l.queue <- asyncEvent{keyvals: kvs}:
+	default:
+		atomic.AddUint64(&l.dropped, 1)
+	}
+	return nil
+}
+
+// Flush blocks until the events queued before the call have been passed to
+// the wrapped logger.
+func (l *AsyncLogger) Flush() {
+	l.mtx.RLock()
+	if l.closed {
+		l.mtx.RUnlock()
+		return
+	}
+	flushed := make(chan struct{})
+	l.queue <- asyncEvent{flushed: flushed}
+	l.mtx.RUnlock()
+	<-flushed
+}
+
+// Close stops accepting events, and blocks until the queued events have been
+// passed to the wrapped logger. It's safe to call Close more than once.
+func (l *AsyncLogger) Close() error {
+	l.mtx.Lock()
+	if !l.closed {
+		l.closed = true
+		close(l.queue)
+	}
+	l.mtx.Unlock()
+	<-l.done
+	return nil
+}
+
+// Dropped returns the number of events dropped because the buffer was full.
+func (l *AsyncLogger) Dropped() uint64 {
+	return atomic.LoadUint64(&l.dropped)
+}
+
+func (l *AsyncLogger) loop() {
+	defer close(l.done)
+	for e := range l.queue {
+		if e.flushed != nil {
+			close(e.flushed)
+			continue
+		}
+		if err := l.logger.Log(e.keyvals...); err != nil {
+			l.errorHandler(err)
+		}
+	}
+}
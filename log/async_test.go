@@ -0,0 +1,108 @@
+package log_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/guherbozdogan/kit/log"
+)
+
+func TestAsyncLogger(t *testing.T) {
+	t.Parallel()
+	buf := &bytes.Buffer{}
+	logger := log.NewAsyncLogger(log.NewLogfmtLogger(buf), 4)
+
+	for i := 0; i < 10; i++ {
+		if err := log.With(logger, "i", i).Log("msg", "hi"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	logger.Flush()
+
+	var want bytes.Buffer
+	for i := 0; i < 10; i++ {
+		fmt.Fprintf(&want, "i=%d msg=hi\n", i)
+	}
+	if want, have := want.String(), buf.String(); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if want, have := log.ErrAsyncLoggerClosed, logger.Log("msg", "late"); want != have {
+		t.Errorf("want %v, have %v", want, have)
+	}
+}
+
+func TestAsyncLoggerBindsValuersOnLog(t *testing.T) {
+	t.Parallel()
+	var (
+		buf   = &bytes.Buffer{}
+		count = 0
+		inc   = log.Valuer(func() interface{} { count++; return count })
+	)
+	logger := log.NewAsyncLogger(log.With(log.NewLogfmtLogger(buf), "n", inc), 4)
+
+	logger.Log("msg", "a")
+	if want, have := 1, count; want != have {
+		t.Errorf("want %d, have %d", want, have)
+	}
+	logger.Log("msg", "b")
+	logger.Close()
+
+	if want, have := "n=1 msg=a\nn=2 msg=b\n", buf.String(); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+}
+
+func TestAsyncLoggerDrop(t *testing.T) {
+	t.Parallel()
+	var (
+		release = make(chan struct{})
+		mtx     sync.Mutex
+		logged  int
+	)
+	next := log.LoggerFunc(func(keyvals ...interface{}) error {
+		<-release // stall the writer
+		mtx.Lock()
+		logged++
+		mtx.Unlock()
+		return nil
+	})
+	logger := log.NewAsyncLogger(next, 2, log.AsyncDrop())
+
+	// The writer holds at most one event while stalled, and the buffer two,
+	// so at least 2 of 5 events are dropped.
+	for i := 0; i < 5; i++ {
+		logger.Log("i", i)
+	}
+	close(release)
+	logger.Close()
+
+	if logger.Dropped() < 2 {
+		t.Errorf("want at least 2 dropped, have %d", logger.Dropped())
+	}
+	if want, have := uint64(5), uint64(logged)+logger.Dropped(); want != have {
+		t.Errorf("want %d, have %d", want, have)
+	}
+}
+
+func TestAsyncLoggerErrorHandler(t *testing.T) {
+	t.Parallel()
+	var (
+		errLog = errors.New("log")
+		have   error
+	)
+	next := log.LoggerFunc(func(...interface{}) error { return errLog })
+	logger := log.NewAsyncLogger(next, 1, log.AsyncErrorHandler(func(err error) { have = err }))
+	logger.Log("msg", "hi")
+	logger.Close()
+
+	if want := errLog; want != have {
+		t.Errorf("want %v, have %v", want, have)
+	}
+}
@@ -0,0 +1,20 @@
+// Package file provides a rotating file writer for log output. The writer is
+// safe for concurrent use, and passes each Write to the file in one call, so
+// it can be given to the loggers of package log without a sync writer.
+//
+//    w, err := file.NewWriter("/var/log/app.log",
+//        file.MaxSize(100<<20),
+//        file.MaxBackups(7),
+//        file.Compress(),
+//    )
+//    if err != nil {
+//        panic(err)
+//    }
+//    defer w.Close()
+//    logger := log.NewLogfmtLogger(w)
+//
+// When the file is rotated, it's renamed to a backup with the time of the
+// rotation in its name, e.g. app-2017-04-08T15-04-05.000000000.log, and a new
+// file is created. Backups may be compressed with gzip, and only the most
+// recent ones kept.
+package file
@@ -0,0 +1,17 @@
+package file
+
+import "os"
+
+// SetRename replaces the function the writer renames files with.
+func (w *Writer) SetRename(f func(oldpath, newpath string) error) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	w.rename = f
+}
+
+// SetOpenFile replaces the function the writer opens files with.
+func (w *Writer) SetOpenFile(f func(name string, flag int, perm os.FileMode) (*os.File, error)) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	w.openFile = f
+}
@@ -0,0 +1,315 @@
+package file
+
+import (
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat is the format of the rotation time in backup names. It
+// sorts lexically, and the nanoseconds keep rapid rotations apart.
+const backupTimeFormat = "2006-01-02T15-04-05.000000000"
+
+// Writer is an io.Writer that writes to a file, and rotates it when it grows
+// beyond a maximum size, or at a fixed interval. Writer is safe for concurrent
+// use by multiple goroutines.
+type Writer struct {
+	filename   string
+	maxSize    int64
+	interval   time.Duration
+	maxBackups int
+	compress   bool
+
+	// rename and openFile are os.Rename and os.OpenFile, except in tests.
+	rename   func(oldpath, newpath string) error
+	openFile func(name string, flag int, perm os.FileMode) (*os.File, error)
+
+	mtx      sync.Mutex
+	closed   bool
+	file     *os.File // nil if reopening it failed
+	size     int64
+	rotateAt time.Time // zero if not rotating by time
+	retryAt  time.Time // no rotation by size or time before, after a failure
+
+	millc chan struct{}
+	done  chan struct{}
+}
+
+// rotateRetryInterval is how long the writer waits before rotating again after
+// a rotation failed, instead of trying on every write.
+const rotateRetryInterval = time.Second
+
+// Option sets an optional parameter for writers.
+type Option func(*Writer)
+
+// MaxSize rotates the file before a write would grow it beyond n bytes. A
+// single write larger than n is written to a file of its own. By default,
+// files aren't rotated by size.
+func MaxSize(n int64) Option {
+	return func(w *Writer) { w.maxSize = n }
+}
+
+// Interval rotates the file when the current interval elapses. Intervals are
+// aligned to the zero time in UTC, so an interval of 24 hours rotates the file
+// at midnight UTC. By default, files aren't rotated by time.
+func Interval(d time.Duration) Option {
+	return func(w *Writer) { w.interval = d }
+}
+
+// MaxBackups keeps only the n most recent backups, and removes older ones. By
+// default, all backups are kept.
+func MaxBackups(n int) Option {
+	return func(w *Writer) { w.maxBackups = n }
+}
+
+// Compress compresses backups with gzip, adding a ".gz" extension.
+func Compress() Option {
+	return func(w *Writer) { w.compress = true }
+}
+
+// NewWriter opens the named file for appending, creating it and its
+// directory if necessary, and returns a writer that rotates it as configured
+// by the options.
+func NewWriter(filename string, options ...Option) (*Writer, error) {
+	w := &Writer{
+		filename: filename,
+		rename:   os.Rename,
+		openFile: os.OpenFile,
+		millc:    make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+	for _, option := range options {
+		option(w)
+	}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	go w.mill()
+	return w, nil
+}
+
+// Write implements io.Writer, rotating the file first if necessary. If the
+// rotation fails, p is still written to the current file if possible, and the
+// rotation error is returned; rotating is tried again a second later.
+func (w *Writer) Write(p []byte) (n int, err error) {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+
+	if w.closed {
+		return 0, os.ErrClosed
+	}
+	if w.file == nil {
+		// A rotation failed to open the new file; try again.
+		if err := w.open(); err != nil {
+			return 0, err
+		}
+	}
+	var rotateErr error
+	if w.due(int64(len(p))) {
+		if rotateErr = w.rotate(); rotateErr != nil {
+			w.retryAt = time.Now().Add(rotateRetryInterval)
+			if w.file == nil && w.open() != nil {
+				return 0, rotateErr
+			}
+		}
+	}
+	n, err = w.file.Write(p)
+	w.size += int64(n)
+	if err == nil {
+		err = rotateErr
+	}
+	return n, err
+}
+
+// Rotate closes the file, renames it to a backup and opens a new file,
+// regardless of its size and age. It may be used to rotate on a signal.
+func (w *Writer) Rotate() error {
+	w.mtx.Lock()
+	defer w.mtx.Unlock()
+	if w.closed {
+		return os.ErrClosed
+	}
+	return w.rotate()
+}
+
+// Close closes the file, and waits for pending compression and removal of
+// backups.
+func (w *Writer) Close() error {
+	w.mtx.Lock()
+	if w.closed {
+		w.mtx.Unlock()
+		return nil
+	}
+	w.closed = true
+	var err error
+	if w.file != nil {
+		err = w.file.Close()
+		w.file = nil
+	}
+	close(w.millc)
+	w.mtx.Unlock()
+
+	<-w.done
+	return err
+}
+
+func (w *Writer) due(n int64) bool {
+	if time.Now().Before(w.retryAt) {
+		return false
+	}
+	if w.maxSize > 0 && w.size > 0 && w.size+n > w.maxSize {
+		return true
+	}
+	return !w.rotateAt.IsZero() && !time.Now().Before(w.rotateAt)
+}
+
+func (w *Writer) open() error {
+	if err := os.MkdirAll(filepath.Dir(w.filename), 0755); err != nil {
+		return err
+	}
+	f, err := w.openFile(w.filename, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+	w.file = f
+	w.size = fi.Size()
+	if w.interval > 0 {
+		w.rotateAt = time.Now().UTC().Truncate(w.interval).Add(w.interval)
+	}
+	return nil
+}
+
+// rotate closes the file, renames it to a backup and opens a new file. If the
+// rename fails, the file is opened again, so that writes continue to go to it.
+// If opening fails, the file is left nil, and the next write tries again.
+func (w *Writer) rotate() error {
+	if w.file != nil {
+		err := w.file.Close()
+		w.file = nil
+		if err != nil {
+			return err
+		}
+	}
+	if err := w.rename(w.filename, w.backupName(time.Now())); err != nil && !os.IsNotExist(err) {
+		w.open()
+		return err
+	}
+	if err := w.open(); err != nil {
+		return err
+	}
+
+	// Leave compression and removal of backups to the mill.
+	select {
+	case w.millc <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// backupName returns the name of a backup rotated at t: the name of the file
+// with the time inserted before its extension.
+func (w *Writer) backupName(t time.Time) string {
+	ext := filepath.Ext(w.filename)
+	prefix := strings.TrimSuffix(w.filename, ext)
+	return prefix + "-" + t.UTC().Format(backupTimeFormat) + ext
+}
+
+// mill compresses and removes backups after rotations, so that writes don't
+// wait for it.
+func (w *Writer) mill() {
+	defer close(w.done)
+	for range w.millc {
+		w.millBackups()
+	}
+}
+
+func (w *Writer) millBackups() {
+	backups, err := w.backups()
+	if err != nil {
+		return
+	}
+
+	if w.maxBackups > 0 && len(backups) > w.maxBackups {
+		for _, b := range backups[w.maxBackups:] {
+			os.Remove(b)
+		}
+		backups = backups[:w.maxBackups]
+	}
+
+	if w.compress {
+		for _, b := range backups {
+			if !strings.HasSuffix(b, ".gz") {
+				compressFile(b)
+			}
+		}
+	}
+}
+
+// backups returns the backups of the file, most recent first.
+func (w *Writer) backups() ([]string, error) {
+	dir := filepath.Dir(w.filename)
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		ext    = filepath.Ext(w.filename)
+		prefix = strings.TrimSuffix(filepath.Base(w.filename), ext) + "-"
+		times  = map[string]string{}
+		names  []string
+	)
+	for _, e := range entries {
+		name := strings.TrimSuffix(e.Name(), ".gz")
+		if e.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, ext) {
+			continue
+		}
+		ts := strings.TrimSuffix(strings.TrimPrefix(name, prefix), ext)
+		if _, err := time.Parse(backupTimeFormat, ts); err != nil {
+			continue
+		}
+		path := filepath.Join(dir, e.Name())
+		times[path] = ts
+		names = append(names, path)
+	}
+	sort.Slice(names, func(i, j int) bool { return times[names[i]] > times[names[j]] })
+	return names, nil
+}
+
+// compressFile gzips the file to a file with a ".gz" extension, and removes
+// it. The original is kept if compression fails.
+func compressFile(name string) error {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(name+".gz", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(dst)
+	if _, err = io.Copy(gz, src); err == nil {
+		err = gz.Close()
+	}
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(name + ".gz")
+		return err
+	}
+	return os.Remove(name)
+}
@@ -0,0 +1,259 @@
+package file_test
+
+import (
+	"compress/gzip"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/guherbozdogan/kit/log"
+	"github.com/guherbozdogan/kit/log/file"
+)
+
+func TestWriterMaxSize(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "app.log")
+
+	w, err := file.NewWriter(filename, file.MaxSize(20))
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := log.NewLogfmtLogger(w)
+	for i := 0; i < 3; i++ {
+		logger.Log("msg", "0123456789") // 15 bytes
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if want, have := []string{"app.log", "app-*.log", "app-*.log"}, names(t, dir); !match(want, have) {
+		t.Errorf("want %v, have %v", want, have)
+	}
+	if want, have := "msg=0123456789\n", read(t, filename); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+}
+
+func TestWriterMaxBackupsAndCompress(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "app.log")
+
+	w, err := file.NewWriter(filename, file.MaxBackups(2), file.Compress())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 4; i++ {
+		w.Write([]byte{'a' + byte(i), '\n'})
+		if err := w.Rotate(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	w.Write([]byte("e\n"))
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	have := names(t, dir)
+	if want := []string{"app.log", "app-*.log.gz", "app-*.log.gz"}; !match(want, have) {
+		t.Fatalf("want %v, have %v", want, have)
+	}
+	// Most recent backups are kept.
+	if want, have := "c\n", read(t, filepath.Join(dir, have[1])); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+	if want, have := "d\n", read(t, filepath.Join(dir, have[2])); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+}
+
+func TestWriterInterval(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "app.log")
+
+	w, err := file.NewWriter(filename, file.Interval(20*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("a\n"))
+	time.Sleep(50 * time.Millisecond)
+	w.Write([]byte("b\n"))
+	w.Close()
+
+	if want, have := []string{"app.log", "app-*.log"}, names(t, dir); !match(want, have) {
+		t.Errorf("want %v, have %v", want, have)
+	}
+	if want, have := "b\n", read(t, filename); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+}
+
+func TestWriterAppends(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "sub", "app.log")
+
+	for _, s := range []string{"a\n", "b\n"} {
+		w, err := file.NewWriter(filename)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(s))
+		w.Close()
+	}
+	if want, have := "a\nb\n", read(t, filename); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+}
+
+func TestWriterRenameFailure(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "app.log")
+
+	w, err := file.NewWriter(filename, file.MaxSize(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("a\n"))
+
+	errRename := errors.New("rename failed")
+	w.SetRename(func(string, string) error { return errRename })
+	// The write that triggers the rotation is written nevertheless, and so
+	// are the ones after it, without trying to rotate again right away.
+	if n, err := w.Write([]byte("b\n")); n != 2 || err != errRename {
+		t.Errorf("want 2, %v, have %d, %v", errRename, n, err)
+	}
+	if n, err := w.Write([]byte("c\n")); n != 2 || err != nil {
+		t.Errorf("want 2, <nil>, have %d, %v", n, err)
+	}
+	if want, have := "a\nb\nc\n", read(t, filename); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+
+	w.SetRename(os.Rename)
+	if err := w.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("d\n"))
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if want, have := []string{"app.log", "app-*.log"}, names(t, dir); !match(want, have) {
+		t.Errorf("want %v, have %v", want, have)
+	}
+	if want, have := "d\n", read(t, filename); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+}
+
+func TestWriterOpenFailure(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "app.log")
+
+	w, err := file.NewWriter(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("a\n"))
+
+	errOpen := errors.New("open failed")
+	w.SetOpenFile(func(string, int, os.FileMode) (*os.File, error) { return nil, errOpen })
+	if err := w.Rotate(); err != errOpen {
+		t.Errorf("want %v, have %v", errOpen, err)
+	}
+	// Without a file, writes fail until the file can be opened again.
+	if _, err := w.Write([]byte("b\n")); err != errOpen {
+		t.Errorf("want %v, have %v", errOpen, err)
+	}
+
+	w.SetOpenFile(os.OpenFile)
+	if _, err := w.Write([]byte("c\n")); err != nil {
+		t.Fatal(err)
+	}
+	if want, have := "c\n", read(t, filename); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+
+	// Close is idempotent, also after a failed open.
+	w.SetOpenFile(func(string, int, os.FileMode) (*os.File, error) { return nil, errOpen })
+	w.Rotate()
+	for i := 0; i < 2; i++ {
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := w.Write([]byte("d\n")); err != os.ErrClosed {
+		t.Errorf("want %v, have %v", os.ErrClosed, err)
+	}
+}
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "file_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+// names returns the sorted names of the files in dir. Backups sort before
+// the file, and in the order they were rotated.
+func names(t *testing.T, dir string) []string {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+	if len(names) > 0 {
+		// "app-..." sorts before "app.log"; move the file to the front.
+		names = append(names[len(names)-1:], names[:len(names)-1]...)
+	}
+	return names
+}
+
+func match(patterns, names []string) bool {
+	if len(patterns) != len(names) {
+		return false
+	}
+	for i := range patterns {
+		if ok, _ := filepath.Match(patterns[i], names[i]); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func read(t *testing.T, filename string) string {
+	f, err := os.Open(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var b []byte
+	if strings.HasSuffix(filename, ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err = ioutil.ReadAll(gz)
+	} else {
+		b, err = ioutil.ReadAll(f)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
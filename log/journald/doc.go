@@ -0,0 +1,18 @@
+// Package journald provides a logger that sends log events to the systemd
+// journal using its native protocol. Log events with a level from package
+// log/level are mapped to the matching PRIORITY field, the message key becomes
+// the MESSAGE field, and all other keyvals are sent as fields of their own.
+//
+//    conn, err := journald.Dial(journald.DefaultSocket)
+//    if err != nil {
+//        panic(err)
+//    }
+//    logger := journald.NewLogger(conn, journald.SyslogIdentifier("addsvc"))
+//    level.Info(logger).Log("msg", "starting", "http_addr", ":8080")
+//
+// Keys are converted to valid journal field names: uppercase letters, digits
+// and underscores, e.g. "http.addr" becomes HTTP_ADDR. Keys that would become
+// fields the journal interprets are prefixed with X_, e.g. "priority" becomes
+// X_PRIORITY. Log events must fit in a single datagram; the journal's limit is
+// typically large enough for ordinary log events.
+package journald
@@ -0,0 +1,180 @@
+package journald
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/guherbozdogan/kit/log"
+	"github.com/guherbozdogan/kit/log/level"
+)
+
+// DefaultSocket is the path of the journal's native protocol socket.
+const DefaultSocket = "/run/systemd/journal/socket"
+
+// Dial connects to the journal socket at path.
+func Dial(path string) (net.Conn, error) {
+	return net.Dial("unixgram", path)
+}
+
+// Syslog priorities, as used by the PRIORITY field.
+const (
+	PriEmerg = iota
+	PriAlert
+	PriCrit
+	PriErr
+	PriWarning
+	PriNotice
+	PriInfo
+	PriDebug
+)
+
+// LevelPriority returns the priority of a level from package log/level.
+func LevelPriority(v level.Value) int {
+	switch v {
+	case level.ErrorValue():
+		return PriErr
+	case level.WarnValue():
+		return PriWarning
+	case level.InfoValue():
+		return PriInfo
+	case level.DebugValue():
+		return PriDebug
+	}
+	return PriNotice
+}
+
+type logger struct {
+	w                io.Writer
+	defaultPriority  int
+	syslogIdentifier string
+	messageKey       interface{}
+}
+
+// Option sets an optional parameter for journald loggers.
+type Option func(*logger)
+
+// DefaultPriority sets the priority of log events without a level. By
+// default, PriNotice is used.
+func DefaultPriority(p int) Option {
+	return func(l *logger) { l.defaultPriority = p }
+}
+
+// SyslogIdentifier sets the SYSLOG_IDENTIFIER field of log events, which
+// journalctl shows as the source of log events. By default, the field is
+// omitted, and journald uses the executable name.
+func SyslogIdentifier(id string) Option {
+	return func(l *logger) { l.syslogIdentifier = id }
+}
+
+// MessageKey sets the key whose value is sent as the MESSAGE field. By
+// default, "msg" is used.
+func MessageKey(key interface{}) Option {
+	return func(l *logger) { l.messageKey = key }
+}
+
+// NewLogger returns a logger that writes log events to w in the journal's
+// native protocol, typically a connection made by Dial. Each log event
+// produces no more than one call to w.Write. The passed Writer must be safe
+// for concurrent use by multiple goroutines if the returned Logger will be
+// used concurrently.
+func NewLogger(w io.Writer, options ...Option) log.Logger {
+	l := &logger{
+		w:               w,
+		defaultPriority: PriNotice,
+		messageKey:      "msg",
+	}
+	for _, option := range options {
+		option(l)
+	}
+	return l
+}
+
+func (l *logger) Log(keyvals ...interface{}) error {
+	var (
+		priority = l.defaultPriority
+		buf      bytes.Buffer
+	)
+	for i := 0; i < len(keyvals); i += 2 {
+		k := keyvals[i]
+		var v interface{} = log.ErrMissingValue
+		if i+1 < len(keyvals) {
+			v = keyvals[i+1]
+		}
+		if lv, ok := v.(level.Value); ok {
+			priority = LevelPriority(lv)
+			continue
+		}
+		name := fieldName(fmt.Sprint(k))
+		if k == l.messageKey {
+			name = "MESSAGE"
+		}
+		writeField(&buf, name, formatValue(v))
+	}
+	writeField(&buf, "PRIORITY", strconv.Itoa(priority))
+	if l.syslogIdentifier != "" {
+		writeField(&buf, "SYSLOG_IDENTIFIER", l.syslogIdentifier)
+	}
+
+	_, err := l.w.Write(buf.Bytes())
+	return err
+}
+
+// writeField writes a field in the native protocol: NAME=value, or for values
+// with newlines, the name, the length of the value and the value itself.
+func writeField(buf *bytes.Buffer, name, value string) {
+	buf.WriteString(name)
+	if !strings.ContainsRune(value, '\n') {
+		buf.WriteByte('=')
+		buf.WriteString(value)
+		buf.WriteByte('\n')
+		return
+	}
+	buf.WriteByte('\n')
+	binary.Write(buf, binary.LittleEndian, uint64(len(value)))
+	buf.WriteString(value)
+	buf.WriteByte('\n')
+}
+
+func formatValue(v interface{}) string {
+	if v == nil {
+		return "null"
+	}
+	return fmt.Sprint(v)
+}
+
+// fieldName returns s as a valid field name: up to 64 uppercase letters,
+// digits and underscores, starting with a letter. Field names starting with
+// an underscore are reserved for fields added by journald, and names of fields
+// the journal interprets, such as PRIORITY, are prefixed with X_ so that keys
+// can't override them.
+func fieldName(s string) string {
+	b := []byte(strings.ToUpper(s))
+	for i, c := range b {
+		if !('A' <= c && c <= 'Z' || '0' <= c && c <= '9') {
+			b[i] = '_'
+		}
+	}
+	name := strings.TrimLeft(string(b), "_")
+	if name == "" || ('0' <= name[0] && name[0] <= '9') || reserved(name) {
+		name = "X_" + name
+	}
+	if len(name) > 64 {
+		name = name[:64]
+	}
+	return name
+}
+
+// reserved reports whether name is a field the journal interprets, which is
+// set by the logger itself or by the caller's source location.
+func reserved(name string) bool {
+	switch name {
+	case "MESSAGE", "MESSAGE_ID", "PRIORITY":
+		return true
+	}
+	return strings.HasPrefix(name, "SYSLOG_") || strings.HasPrefix(name, "CODE_")
+}
@@ -0,0 +1,80 @@
+package journald_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/guherbozdogan/kit/log"
+	"github.com/guherbozdogan/kit/log/journald"
+	"github.com/guherbozdogan/kit/log/level"
+)
+
+func TestLoggerUnixSocket(t *testing.T) {
+	dir, err := ioutil.TempDir("", "journald_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "socket")
+	listener, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	conn, err := journald.Dial(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	logger := journald.NewLogger(conn, journald.SyslogIdentifier("app"))
+	logger = log.With(logger, "http.addr", ":8080")
+	if err := level.Error(logger).Log("msg", "failed", "err", "line 1\nline 2"); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := make([]byte, 1024)
+	n, err := listener.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var want bytes.Buffer
+	want.WriteString("HTTP_ADDR=:8080\nMESSAGE=failed\nERR\n")
+	binary.Write(&want, binary.LittleEndian, uint64(len("line 1\nline 2")))
+	want.WriteString("line 1\nline 2\nPRIORITY=3\nSYSLOG_IDENTIFIER=app\n")
+	if want, have := want.String(), string(buf[:n]); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+}
+
+func TestLoggerFieldNames(t *testing.T) {
+	for _, tc := range []struct {
+		key  interface{}
+		want string
+	}{
+		{"a", "A=1\n"},
+		{"_hidden", "HIDDEN=1\n"},
+		{"9lives", "X_9LIVES=1\n"},
+		{"trace-id", "TRACE_ID=1\n"},
+		{"", "X_=1\n"},
+		{"priority", "X_PRIORITY=1\n"},
+		{"message", "X_MESSAGE=1\n"},
+		{"syslog_identifier", "X_SYSLOG_IDENTIFIER=1\n"},
+		{"code.file", "X_CODE_FILE=1\n"},
+		{"messages", "MESSAGES=1\n"},
+	} {
+		var buf bytes.Buffer
+		journald.NewLogger(&buf).Log(tc.key, 1)
+		if want, have := tc.want+"PRIORITY=5\n", buf.String(); want != have {
+			t.Errorf("%q: want %q, have %q", tc.key, want, have)
+		}
+	}
+}
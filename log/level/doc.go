@@ -19,4 +19,13 @@
 // NewFilter allows precise control over what happens when a log event is
 // emitted without a level key, or if a squelched level is used. Check the
 // Option functions for details.
+//
+// NewDynamic returns a filter whose threshold can be changed at runtime, per
+// component if needed, and which serves its thresholds over HTTP.
+//
+//    filter, err := level.NewDynamic(logger, level.ThresholdInfo)
+//    http.Handle("/debug/loglevel", filter)
+//
+// A threshold may be set with a time after which the previous one is
+// restored, so that verbosity raised during an incident drops back on its own.
 package level
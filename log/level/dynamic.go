@@ -0,0 +1,291 @@
+package level
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/guherbozdogan/kit/log"
+)
+
+// Threshold names accepted by Dynamic, from most to least verbose.
+const (
+	ThresholdDebug = "debug"
+	ThresholdInfo  = "info"
+	ThresholdWarn  = "warn"
+	ThresholdError = "error"
+	ThresholdNone  = "none"
+)
+
+// ErrUnknownThreshold is returned for threshold names other than the
+// Threshold constants.
+var ErrUnknownThreshold = errors.New("unknown threshold")
+
+var thresholds = map[string]level{
+	ThresholdDebug: levelError | levelWarn | levelInfo | levelDebug,
+	ThresholdInfo:  levelError | levelWarn | levelInfo,
+	ThresholdWarn:  levelError | levelWarn,
+	ThresholdError: levelError,
+	ThresholdNone:  0,
+}
+
+// Dynamic is a level filter like NewFilter, but with a threshold that can be
+// changed at runtime, e.g. to raise verbosity during an incident. Thresholds
+// may also be set per component, identified by the value of a component key
+// in log events. Log events with no level are passed to the wrapped logger.
+//
+// Dynamic implements http.Handler to show and change its thresholds, see
+// ServeHTTP.
+type Dynamic struct {
+	next         log.Logger
+	componentKey interface{}
+	allowed      uint32       // level of the default threshold, accessed atomically
+	components   atomic.Value // map[string]string
+
+	mtx     sync.Mutex
+	reverts map[string]*revert
+}
+
+// revert restores a threshold when its timer fires.
+type revert struct {
+	timer *time.Timer
+	at    time.Time
+	to    string // empty to remove a component threshold
+}
+
+// DynamicOption sets an optional parameter for dynamic filters.
+type DynamicOption func(*Dynamic)
+
+// ComponentKey sets the key identifying the component of log events. By
+// default, "component" is used.
+func ComponentKey(key interface{}) DynamicOption {
+	return func(d *Dynamic) { d.componentKey = key }
+}
+
+// NewDynamic wraps next and returns a dynamic filter with the given default
+// threshold, one of the Threshold constants.
+func NewDynamic(next log.Logger, threshold string, options ...DynamicOption) (*Dynamic, error) {
+	allowed, ok := thresholds[threshold]
+	if !ok {
+		return nil, ErrUnknownThreshold
+	}
+	d := &Dynamic{
+		next:         next,
+		componentKey: "component",
+		allowed:      uint32(allowed),
+		reverts:      map[string]*revert{},
+	}
+	d.components.Store(map[string]string{})
+	for _, option := range options {
+		option(d)
+	}
+	return d, nil
+}
+
+// Log implements log.Logger.
+func (d *Dynamic) Log(keyvals ...interface{}) error {
+	var (
+		lv        *levelValue
+		component string
+		hasComp   bool
+	)
+	for i := 0; i < len(keyvals)-1; i += 2 {
+		if v, ok := keyvals[i+1].(*levelValue); ok && lv == nil {
+			lv = v
+		}
+		if keyvals[i] == d.componentKey && !hasComp {
+			component, hasComp = fmt.Sprint(keyvals[i+1]), true
+		}
+	}
+	if lv == nil {
+		return d.next.Log(keyvals...)
+	}
+
+	allowed := level(atomic.LoadUint32(&d.allowed))
+	if hasComp {
+		if threshold, ok := d.components.Load().(map[string]string)[component]; ok {
+			allowed = thresholds[threshold]
+		}
+	}
+	if allowed&lv.level == 0 {
+		return nil
+	}
+	return d.next.Log(keyvals...)
+}
+
+// Set sets the threshold of a component, or the default threshold if the
+// component is empty. If revertAfter is positive, the previous threshold is
+// restored after that time; setting a threshold again before then keeps the
+// original threshold to restore.
+func (d *Dynamic) Set(component, threshold string, revertAfter time.Duration) error {
+	if _, ok := thresholds[threshold]; !ok {
+		return ErrUnknownThreshold
+	}
+
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+
+	r, pending := d.reverts[component]
+	if pending {
+		r.timer.Stop()
+		delete(d.reverts, component)
+	}
+	if revertAfter > 0 {
+		to := d.get(component)
+		if pending {
+			to = r.to
+		}
+		// Always a new revert, so that the superseded one's timer, which may
+		// have fired already, doesn't restore the threshold.
+		next := &revert{to: to, at: time.Now().Add(revertAfter)}
+		next.timer = time.AfterFunc(revertAfter, func() { d.revert(component, next) })
+		d.reverts[component] = next
+	}
+	d.set(component, threshold)
+	return nil
+}
+
+// Unset removes the threshold of a component, so that the default threshold
+// applies to it, and cancels a pending revert.
+func (d *Dynamic) Unset(component string) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	if r, ok := d.reverts[component]; ok {
+		r.timer.Stop()
+		delete(d.reverts, component)
+	}
+	d.set(component, "")
+}
+
+// Thresholds returns the default threshold, and the thresholds per component.
+func (d *Dynamic) Thresholds() (threshold string, components map[string]string) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	components = map[string]string{}
+	for k, v := range d.components.Load().(map[string]string) {
+		components[k] = v
+	}
+	return d.get(""), components
+}
+
+func (d *Dynamic) revert(component string, r *revert) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	if d.reverts[component] != r {
+		return // superseded
+	}
+	delete(d.reverts, component)
+	d.set(component, r.to)
+}
+
+// get returns the threshold of a component, or the default threshold if the
+// component is empty. The mutex must be held.
+func (d *Dynamic) get(component string) string {
+	if component != "" {
+		return d.components.Load().(map[string]string)[component]
+	}
+	allowed := level(atomic.LoadUint32(&d.allowed))
+	for name, l := range thresholds {
+		if l == allowed {
+			return name
+		}
+	}
+	return ""
+}
+
+// set sets the threshold of a component, removing it if threshold is empty,
+// or the default threshold if the component is empty. The mutex must be held.
+func (d *Dynamic) set(component, threshold string) {
+	if component == "" {
+		atomic.StoreUint32(&d.allowed, uint32(thresholds[threshold]))
+		return
+	}
+	// Copy on write, so that Log reads the map without locking.
+	old := d.components.Load().(map[string]string)
+	components := make(map[string]string, len(old)+1)
+	for k, v := range old {
+		components[k] = v
+	}
+	if threshold == "" {
+		delete(components, component)
+	} else {
+		components[component] = threshold
+	}
+	d.components.Store(components)
+}
+
+// thresholdsJSON is the representation of the thresholds used by ServeHTTP.
+type thresholdsJSON struct {
+	Threshold  string               `json:"threshold"`
+	Components map[string]string    `json:"components"`
+	Reverts    map[string]time.Time `json:"reverts,omitempty"`
+}
+
+// changeJSON is the request body of a PUT to ServeHTTP.
+type changeJSON struct {
+	Component   string `json:"component"`
+	Threshold   string `json:"threshold"`
+	RevertAfter string `json:"revert_after"`
+}
+
+// ServeHTTP implements http.Handler. GET responds with the thresholds as
+// JSON, e.g.
+//
+//    {"threshold":"info","components":{"db":"debug"},"reverts":{"db":"2017-04-08T15:04:05Z"}}
+//
+// where reverts holds the times at which thresholds are restored, with the
+// empty component for the default threshold. PUT changes a threshold, and
+// responds like GET. Its body is a JSON object like
+//
+//    {"component":"db","threshold":"debug","revert_after":"15m"}
+//
+// where the component may be omitted to change the default threshold, and
+// revert_after is optional. An empty threshold removes the threshold of the
+// component.
+func (d *Dynamic) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+	case "PUT":
+		var change changeJSON
+		if err := json.NewDecoder(r.Body).Decode(&change); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var revertAfter time.Duration
+		if change.RevertAfter != "" {
+			var err error
+			if revertAfter, err = time.ParseDuration(change.RevertAfter); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+		if change.Threshold == "" && change.Component != "" {
+			d.Unset(change.Component)
+		} else if err := d.Set(change.Component, change.Threshold, revertAfter); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, PUT")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	var resp thresholdsJSON
+	resp.Threshold, resp.Components = d.Thresholds()
+	d.mtx.Lock()
+	if len(d.reverts) > 0 {
+		resp.Reverts = map[string]time.Time{}
+		for component, r := range d.reverts {
+			resp.Reverts[component] = r.at
+		}
+	}
+	d.mtx.Unlock()
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,42 @@
+package level
+
+import (
+	"testing"
+	"time"
+
+	"github.com/guherbozdogan/kit/log"
+)
+
+func TestDynamicRevertSuperseded(t *testing.T) {
+	d, err := NewDynamic(log.NewNopLogger(), ThresholdInfo)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The first revert's timer fires, but its callback only gets the mutex
+	// after the threshold was set again.
+	d.Set("", ThresholdDebug, time.Hour)
+	d.mtx.Lock()
+	r := d.reverts[""]
+	d.mtx.Unlock()
+	d.Set("", ThresholdWarn, time.Hour)
+	d.revert("", r)
+
+	threshold, _ := d.Thresholds()
+	if want, have := ThresholdWarn, threshold; want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+
+	// The new revert still restores the original threshold.
+	d.mtx.Lock()
+	r = d.reverts[""]
+	d.mtx.Unlock()
+	if want, have := ThresholdInfo, r.to; want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+	d.revert("", r)
+	threshold, _ = d.Thresholds()
+	if want, have := ThresholdInfo, threshold; want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+}
@@ -0,0 +1,133 @@
+package level_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/guherbozdogan/kit/log"
+	"github.com/guherbozdogan/kit/log/level"
+)
+
+func TestDynamic(t *testing.T) {
+	var buf bytes.Buffer
+	filter, err := level.NewDynamic(log.NewLogfmtLogger(&buf), level.ThresholdInfo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := log.With(filter, "component", "db")
+
+	level.Debug(filter).Log("msg", "a")
+	level.Debug(logger).Log("msg", "b")
+	level.Info(logger).Log("msg", "c")
+	filter.Log("msg", "d")
+	if want, have := "level=info component=db msg=c\nmsg=d\n", buf.String(); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+
+	buf.Reset()
+	if err := filter.Set("db", level.ThresholdDebug, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := filter.Set("", level.ThresholdError, 0); err != nil {
+		t.Fatal(err)
+	}
+	level.Warn(filter).Log("msg", "a")
+	level.Debug(logger).Log("msg", "b")
+	if want, have := "level=debug component=db msg=b\n", buf.String(); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+
+	buf.Reset()
+	filter.Unset("db")
+	level.Warn(logger).Log("msg", "a")
+	if want, have := "", buf.String(); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+
+	if want, have := level.ErrUnknownThreshold, filter.Set("", "verbose", 0); want != have {
+		t.Errorf("want %v, have %v", want, have)
+	}
+}
+
+func TestDynamicRevert(t *testing.T) {
+	filter, err := level.NewDynamic(log.NewNopLogger(), level.ThresholdInfo)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	filter.Set("", level.ThresholdDebug, 20*time.Millisecond)
+	filter.Set("", level.ThresholdWarn, 20*time.Millisecond) // keeps info to restore
+	filter.Set("db", level.ThresholdDebug, 20*time.Millisecond)
+	if threshold, components := filter.Thresholds(); threshold != level.ThresholdWarn || components["db"] != level.ThresholdDebug {
+		t.Fatalf("have %q, %v", threshold, components)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	threshold, components := filter.Thresholds()
+	if want, have := level.ThresholdInfo, threshold; want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+	if want, have := 0, len(components); want != have {
+		t.Errorf("want %d, have %d", want, have)
+	}
+}
+
+func TestDynamicHTTP(t *testing.T) {
+	filter, err := level.NewDynamic(log.NewNopLogger(), level.ThresholdInfo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(filter)
+	defer server.Close()
+
+	for _, tc := range []struct {
+		method, body string
+		code         int
+		threshold    string
+		components   map[string]string
+		reverts      int
+	}{
+		{"GET", "", http.StatusOK, "info", map[string]string{}, 0},
+		{"PUT", `{"threshold":"debug","revert_after":"1h"}`, http.StatusOK, "debug", map[string]string{}, 1},
+		{"PUT", `{"component":"db","threshold":"warn"}`, http.StatusOK, "debug", map[string]string{"db": "warn"}, 1},
+		{"PUT", `{"component":"db"}`, http.StatusOK, "debug", map[string]string{}, 1},
+		{"PUT", `{"threshold":"info"}`, http.StatusOK, "info", map[string]string{}, 0},
+		{"PUT", `{"threshold":"verbose"}`, http.StatusBadRequest, "", nil, 0},
+		{"PUT", `{"threshold":"info","revert_after":"soon"}`, http.StatusBadRequest, "", nil, 0},
+		{"POST", `{}`, http.StatusMethodNotAllowed, "", nil, 0},
+	} {
+		req, _ := http.NewRequest(tc.method, server.URL, strings.NewReader(tc.body))
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want, have := tc.code, resp.StatusCode; want != have {
+			t.Errorf("%s %s: want %d, have %d", tc.method, tc.body, want, have)
+		}
+		if resp.StatusCode != http.StatusOK {
+			continue
+		}
+
+		var have struct {
+			Threshold  string               `json:"threshold"`
+			Components map[string]string    `json:"components"`
+			Reverts    map[string]time.Time `json:"reverts"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&have); err != nil {
+			t.Fatal(err)
+		}
+		if have.Threshold != tc.threshold || len(have.Components) != len(tc.components) || len(have.Reverts) != tc.reverts {
+			t.Errorf("%s %s: want %q %v %d reverts, have %q %v %v", tc.method, tc.body, tc.threshold, tc.components, tc.reverts, have.Threshold, have.Components, have.Reverts)
+		}
+		for k, v := range tc.components {
+			if have.Components[k] != v {
+				t.Errorf("%s %s: want %v, have %v", tc.method, tc.body, tc.components, have.Components)
+			}
+		}
+	}
+}
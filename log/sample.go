@@ -0,0 +1,180 @@
+package log
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Sampler is a logger that protects the wrapped logger from floods of similar
+// log events. Events are grouped by the value of a key, "msg" by default. Per
+// interval, the first events of each group are passed to the wrapped logger,
+// and after that only every so many.
+//
+// Samplers compose with other loggers like any Logger. Wrap a Sampler with
+// With to add context to the events it passes, and wrap it in a level filter
+// to discard unwanted events before they are counted.
+type Sampler struct {
+	next       Logger
+	key        interface{}
+	interval   time.Duration
+	first      uint64
+	thereafter uint64
+	dropped    uint64 // accessed atomically
+
+	mtx    sync.Mutex
+	reset  time.Time
+	counts map[string]uint64
+}
+
+// SamplerOption sets an optional parameter for samplers.
+type SamplerOption func(*Sampler)
+
+// SamplerKey sets the key whose value groups the sampled events. Events
+// without the key form a group of their own. By default, "msg" is used.
+func SamplerKey(key interface{}) SamplerOption {
+	return func(s *Sampler) { s.key = key }
+}
+
+// NewSampler returns a sampler that passes the first events of each group per
+// interval to next, and 1 in thereafter events after that. If thereafter is
+// zero, no events are passed after the first ones.
+func NewSampler(next Logger, interval time.Duration, first, thereafter int, options ...SamplerOption) *Sampler {
+	s := &Sampler{
+		next:       next,
+		key:        "msg",
+		interval:   interval,
+		first:      uint64(first),
+		thereafter: uint64(thereafter),
+		counts:     map[string]uint64{},
+	}
+	for _, option := range options {
+		option(s)
+	}
+	return s
+}
+
+// Log implements Logger. Dropped events are discarded without error.
+func (s *Sampler) Log(keyvals ...interface{}) error {
+	group := groupOf(keyvals, s.key)
+
+	s.mtx.Lock()
+	now := time.Now()
+	if !now.Before(s.reset) {
+		// Start a new interval, forgetting all groups so that the map only
+		// holds the groups seen in one interval.
+		s.counts = map[string]uint64{}
+		s.reset = now.Add(s.interval)
+	}
+	n := s.counts[group] + 1
+	s.counts[group] = n
+	s.mtx.Unlock()
+
+	if n > s.first && (s.thereafter == 0 || (n-s.first)%s.thereafter != 0) {
+		atomic.AddUint64(&s.dropped, 1)
+		return nil
+	}
+	return s.next.Log(keyvals...)
+}
+
+// Dropped returns the number of events dropped since the sampler was created.
+func (s *Sampler) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+// groupOf returns the value of key in keyvals as a string, which is safe to
+// use as a map key regardless of the value's type.
+func groupOf(keyvals []interface{}, key interface{}) string {
+	for i := 0; i < len(keyvals)-1; i += 2 {
+		if keyvals[i] != key {
+			continue
+		}
+		switch v := keyvals[i+1].(type) {
+		case string:
+			return v
+		default:
+			return fmt.Sprint(v)
+		}
+	}
+	return ""
+}
+
+// RateLimiter is a logger that passes events to the wrapped logger at a
+// limited rate, using a token bucket. Events exceeding the rate are dropped,
+// and their number is added to the next event that is passed, under the key
+// "dropped" by default.
+//
+// Like Samplers, RateLimiters compose with With and level filters.
+type RateLimiter struct {
+	next       Logger
+	droppedKey interface{}
+	rate       float64 // tokens per second
+	burst      float64
+	dropped    uint64 // accessed atomically
+
+	mtx     sync.Mutex
+	tokens  float64
+	last    time.Time
+	pending uint64 // dropped since the last passed event
+}
+
+// RateLimiterOption sets an optional parameter for rate limiters.
+type RateLimiterOption func(*RateLimiter)
+
+// RateLimiterDroppedKey sets the key under which the number of dropped events
+// is reported. By default, "dropped" is used.
+func RateLimiterDroppedKey(key interface{}) RateLimiterOption {
+	return func(l *RateLimiter) { l.droppedKey = key }
+}
+
+// NewRateLimiter returns a rate limiter that passes rate events per second to
+// next on average, with bursts of up to burst events.
+func NewRateLimiter(next Logger, rate float64, burst int, options ...RateLimiterOption) *RateLimiter {
+	l := &RateLimiter{
+		next:       next,
+		droppedKey: "dropped",
+		rate:       rate,
+		burst:      float64(burst),
+		tokens:     float64(burst),
+		last:       time.Now(),
+	}
+	for _, option := range options {
+		option(l)
+	}
+	return l
+}
+
+// Log implements Logger. Dropped events are discarded without error.
+func (l *RateLimiter) Log(keyvals ...interface{}) error {
+	l.mtx.Lock()
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+	l.last = now
+	if l.tokens < 1 {
+		l.pending++
+		l.mtx.Unlock()
+		atomic.AddUint64(&l.dropped, 1)
+		return nil
+	}
+	l.tokens--
+	pending := l.pending
+	l.pending = 0
+	l.mtx.Unlock()
+
+	if pending > 0 {
+		kvs := make([]interface{}, len(keyvals), len(keyvals)+2)
+		copy(kvs, keyvals)
+		keyvals = append(kvs, l.droppedKey, pending)
+	}
+	return l.next.Log(keyvals...)
+}
+
+// Dropped returns the number of events dropped since the rate limiter was
+// created.
+func (l *RateLimiter) Dropped() uint64 {
+	return atomic.LoadUint64(&l.dropped)
+}
@@ -0,0 +1,86 @@
+package log_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/guherbozdogan/kit/log"
+	"github.com/guherbozdogan/kit/log/level"
+)
+
+func TestSampler(t *testing.T) {
+	t.Parallel()
+	buf := &bytes.Buffer{}
+	sampler := log.NewSampler(log.NewLogfmtLogger(buf), time.Hour, 2, 3)
+	logger := log.With(sampler, "a", 1)
+
+	for i := 0; i < 10; i++ {
+		logger.Log("msg", "hot")
+	}
+	logger.Log("msg", "cold")
+
+	// 1st, 2nd, 5th and 8th hot event, and the cold one.
+	if want, have := 5, strings.Count(buf.String(), "\n"); want != have {
+		t.Errorf("want %d, have %d", want, have)
+	}
+	if want, have := uint64(6), sampler.Dropped(); want != have {
+		t.Errorf("want %d, have %d", want, have)
+	}
+}
+
+func TestSamplerKey(t *testing.T) {
+	t.Parallel()
+	buf := &bytes.Buffer{}
+	sampler := log.NewSampler(log.NewLogfmtLogger(buf), time.Hour, 1, 0, log.SamplerKey(level.Key()))
+	logger := level.NewFilter(sampler, level.AllowInfo())
+
+	level.Error(logger).Log("msg", "a")
+	level.Error(logger).Log("msg", "b")
+	level.Info(logger).Log("msg", "c")
+	level.Debug(logger).Log("msg", "d")
+
+	if want, have := "level=error msg=a\nlevel=info msg=c\n", buf.String(); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+}
+
+func TestSamplerInterval(t *testing.T) {
+	t.Parallel()
+	buf := &bytes.Buffer{}
+	logger := log.NewSampler(log.NewLogfmtLogger(buf), 10*time.Millisecond, 1, 0)
+
+	logger.Log("msg", "hot")
+	logger.Log("msg", "hot")
+	time.Sleep(20 * time.Millisecond)
+	logger.Log("msg", "hot")
+
+	if want, have := 2, strings.Count(buf.String(), "\n"); want != have {
+		t.Errorf("want %d, have %d", want, have)
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	t.Parallel()
+	buf := &bytes.Buffer{}
+	limiter := log.NewRateLimiter(log.NewLogfmtLogger(buf), 20, 1)
+	logger := log.With(limiter, "a", 1)
+
+	for i := 0; i < 3; i++ {
+		logger.Log("msg", "hot")
+	}
+	if want, have := "a=1 msg=hot\n", buf.String(); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+	if want, have := uint64(2), limiter.Dropped(); want != have {
+		t.Errorf("want %d, have %d", want, have)
+	}
+
+	buf.Reset()
+	time.Sleep(100 * time.Millisecond)
+	logger.Log("msg", "hot")
+	if want, have := "a=1 msg=hot dropped=2\n", buf.String(); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+}
@@ -0,0 +1,17 @@
+// Package syslog provides a logger that sends log events to syslog in the
+// RFC 5424 format. Log events with a level from package log/level are mapped
+// to the matching syslog severity, the message key becomes the syslog message,
+// and all other keyvals are sent as structured data.
+//
+//    conn, err := syslog.DialLocal()
+//    if err != nil {
+//        panic(err)
+//    }
+//    logger := syslog.NewLogger(conn, syslog.AppName("addsvc"))
+//    level.Info(logger).Log("msg", "starting", "addr", ":8080")
+//
+// which sends something like
+//
+//    <14>1 2017-04-08T15:04:05.000000Z host addsvc 42 - [kv@32473 addr=":8080"] starting
+//
+package syslog
@@ -0,0 +1,279 @@
+package syslog
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/guherbozdogan/kit/log"
+	"github.com/guherbozdogan/kit/log/level"
+)
+
+// Facility is a syslog facility.
+type Facility int
+
+// Syslog facilities, as defined by RFC 5424.
+const (
+	Kern Facility = iota
+	User
+	Mail
+	Daemon
+	Auth
+	Syslog
+	LPR
+	News
+	UUCP
+	Cron
+	AuthPriv
+	FTP
+	_ // NTP
+	_ // log audit
+	_ // log alert
+	_ // clock daemon
+	Local0
+	Local1
+	Local2
+	Local3
+	Local4
+	Local5
+	Local6
+	Local7
+)
+
+// Severity is a syslog severity.
+type Severity int
+
+// Syslog severities, as defined by RFC 5424.
+const (
+	Emergency Severity = iota
+	Alert
+	Critical
+	Error
+	Warning
+	Notice
+	Informational
+	Debug
+)
+
+// LevelSeverity returns the severity of a level from package log/level.
+func LevelSeverity(v level.Value) Severity {
+	switch v {
+	case level.ErrorValue():
+		return Error
+	case level.WarnValue():
+		return Warning
+	case level.InfoValue():
+		return Informational
+	case level.DebugValue():
+		return Debug
+	}
+	return Notice
+}
+
+// DefaultSDID is the default ID of the structured data element holding the
+// keyvals. The number is the private enterprise number reserved for
+// documentation by RFC 5612; applications may want to use their own.
+const DefaultSDID = "kv@32473"
+
+// Local syslog sockets, in the order DialLocal tries them.
+var localSockets = []string{"/dev/log", "/var/run/syslog", "/var/run/log"}
+
+// DialLocal connects to the local syslog daemon over a unix socket.
+func DialLocal() (net.Conn, error) {
+	var err error
+	for _, network := range []string{"unixgram", "unix"} {
+		for _, path := range localSockets {
+			var conn net.Conn
+			if conn, err = net.Dial(network, path); err == nil {
+				return conn, nil
+			}
+		}
+	}
+	return nil, err
+}
+
+type logger struct {
+	w               io.Writer
+	facility        Facility
+	defaultSeverity Severity
+	hostname        string
+	appName         string
+	procID          string
+	sdID            string
+	messageKey      interface{}
+	octetCounting   bool
+}
+
+// Option sets an optional parameter for syslog loggers.
+type Option func(*logger)
+
+// WithFacility sets the facility of log events. By default, User is used.
+func WithFacility(f Facility) Option {
+	return func(l *logger) { l.facility = f }
+}
+
+// DefaultSeverity sets the severity of log events without a level. By
+// default, Notice is used.
+func DefaultSeverity(s Severity) Option {
+	return func(l *logger) { l.defaultSeverity = s }
+}
+
+// Hostname sets the hostname of log events. By default, os.Hostname is used.
+func Hostname(hostname string) Option {
+	return func(l *logger) { l.hostname = hostname }
+}
+
+// AppName sets the app name of log events. By default, the base name of the
+// executable is used.
+func AppName(name string) Option {
+	return func(l *logger) { l.appName = name }
+}
+
+// SDID sets the ID of the structured data element holding the keyvals. By
+// default, DefaultSDID is used.
+func SDID(id string) Option {
+	return func(l *logger) { l.sdID = id }
+}
+
+// MessageKey sets the key whose value is sent as the syslog message. By
+// default, "msg" is used.
+func MessageKey(key interface{}) Option {
+	return func(l *logger) { l.messageKey = key }
+}
+
+// OctetCounting prefixes each log event with its length, as required to
+// send syslog over stream transports like TCP by RFC 6587. It's not needed
+// for datagram transports like UDP or unixgram sockets.
+func OctetCounting() Option {
+	return func(l *logger) { l.octetCounting = true }
+}
+
+// NewLogger returns a logger that writes log events to w in the RFC 5424
+// format, typically a connection to a syslog daemon. Each log event produces
+// no more than one call to w.Write. The passed Writer must be safe for
+// concurrent use by multiple goroutines if the returned Logger will be used
+// concurrently.
+func NewLogger(w io.Writer, options ...Option) log.Logger {
+	hostname, _ := os.Hostname()
+	l := &logger{
+		w:               w,
+		facility:        User,
+		defaultSeverity: Notice,
+		hostname:        hostname,
+		appName:         filepath.Base(os.Args[0]),
+		procID:          strconv.Itoa(os.Getpid()),
+		sdID:            DefaultSDID,
+		messageKey:      "msg",
+	}
+	for _, option := range options {
+		option(l)
+	}
+	return l
+}
+
+func (l *logger) Log(keyvals ...interface{}) error {
+	var (
+		severity = l.defaultSeverity
+		msg      string
+		params   bytes.Buffer
+	)
+	for i := 0; i < len(keyvals); i += 2 {
+		k := keyvals[i]
+		var v interface{} = log.ErrMissingValue
+		if i+1 < len(keyvals) {
+			v = keyvals[i+1]
+		}
+		if lv, ok := v.(level.Value); ok {
+			severity = LevelSeverity(lv)
+			continue
+		}
+		if k == l.messageKey {
+			msg = fmt.Sprint(v)
+			continue
+		}
+		params.WriteByte(' ')
+		params.WriteString(paramName(fmt.Sprint(k)))
+		params.WriteString(`="`)
+		paramValueEscaper.WriteString(&params, formatValue(v))
+		params.WriteByte('"')
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "<%d>1 %s %s %s %s - ",
+		int(l.facility)*8+int(severity),
+		time.Now().UTC().Format("2006-01-02T15:04:05.000000Z"),
+		header(l.hostname, 255),
+		header(l.appName, 48),
+		header(l.procID, 128),
+	)
+	if params.Len() == 0 {
+		buf.WriteByte('-')
+	} else {
+		buf.WriteByte('[')
+		buf.WriteString(l.sdID)
+		buf.Write(params.Bytes())
+		buf.WriteByte(']')
+	}
+	if msg != "" {
+		buf.WriteByte(' ')
+		buf.WriteString(msg)
+	}
+
+	p := buf.Bytes()
+	if l.octetCounting {
+		p = append([]byte(strconv.Itoa(len(p))+" "), p...)
+	}
+	_, err := l.w.Write(p)
+	return err
+}
+
+func formatValue(v interface{}) string {
+	if v == nil {
+		return "null"
+	}
+	return fmt.Sprint(v)
+}
+
+var paramValueEscaper = strings.NewReplacer(`"`, `\"`, `\`, `\\`, `]`, `\]`)
+
+// paramName returns s as a valid SD-NAME: up to 32 printable ASCII
+// characters, except '=', ' ', ']' and '"'.
+func paramName(s string) string {
+	b := []byte(s)
+	for i, c := range b {
+		if c < 33 || c > 126 || c == '=' || c == ']' || c == '"' {
+			b[i] = '_'
+		}
+	}
+	if len(b) > 32 {
+		b = b[:32]
+	}
+	if len(b) == 0 {
+		return "_"
+	}
+	return string(b)
+}
+
+// header returns s as a valid header field: up to max printable ASCII
+// characters, or the nil value "-" if empty.
+func header(s string, max int) string {
+	b := []byte(s)
+	for i, c := range b {
+		if c < 33 || c > 126 {
+			b[i] = '_'
+		}
+	}
+	if len(b) > max {
+		b = b[:max]
+	}
+	if len(b) == 0 {
+		return "-"
+	}
+	return string(b)
+}
@@ -0,0 +1,75 @@
+package syslog_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/guherbozdogan/kit/log"
+	"github.com/guherbozdogan/kit/log/level"
+	"github.com/guherbozdogan/kit/log/syslog"
+)
+
+func TestLoggerUnixSocket(t *testing.T) {
+	dir, err := ioutil.TempDir("", "syslog_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	addr := &net.UnixAddr{Name: filepath.Join(dir, "log"), Net: "unixgram"}
+	listener, err := net.ListenUnixgram("unixgram", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	conn, err := net.DialUnix("unixgram", nil, addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	logger := syslog.NewLogger(conn, syslog.WithFacility(syslog.Local0), syslog.Hostname("host"), syslog.AppName("app"))
+	logger = log.With(logger, "a", 1)
+	if err := level.Warn(logger).Log("msg", "hello world", "b", `quote" ]`); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := make([]byte, 1024)
+	n, err := listener.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := regexp.MustCompile(`^<132>1 \S+ host app \d+ - \[kv@32473 a="1" b="quote\\" \\]"\] hello world$`)
+	if have := string(buf[:n]); !want.MatchString(have) {
+		t.Errorf("want %s, have %q", want, have)
+	}
+}
+
+func TestLoggerFormat(t *testing.T) {
+	for _, tc := range []struct {
+		options []syslog.Option
+		keyvals []interface{}
+		want    string
+	}{
+		{nil, []interface{}{"msg", "hi"}, `^<13>1 \S+ h a \d+ - - hi$`},
+		{nil, []interface{}{level.Key(), level.DebugValue()}, `^<15>1 \S+ h a \d+ - -$`},
+		{nil, []interface{}{"key with=space", nil}, `^<13>1 \S+ h a \d+ - \[kv@32473 key_with_space="null"\]$`},
+		{[]syslog.Option{syslog.SDID("x@1"), syslog.MessageKey("m")}, []interface{}{"m", "hi", "msg", "x"}, `^<13>1 \S+ h a \d+ - \[x@1 msg="x"\] hi$`},
+		{[]syslog.Option{syslog.OctetCounting()}, []interface{}{"msg", "hi"}, `^\d+ <13>1 \S+ h a \d+ - - hi$`},
+	} {
+		var buf bytes.Buffer
+		options := append([]syslog.Option{syslog.Hostname("h"), syslog.AppName("a")}, tc.options...)
+		if err := syslog.NewLogger(&buf, options...).Log(tc.keyvals...); err != nil {
+			t.Fatal(err)
+		}
+		if have := buf.String(); !regexp.MustCompile(tc.want).MatchString(have) {
+			t.Errorf("want %s, have %q", tc.want, have)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+// Package opentelemetry provides OpenTelemetry implementations for metrics.
+// Individual metrics are mapped to synchronous OpenTelemetry instruments, and
+// label values are recorded as attributes.
+package opentelemetry
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+
+	kitmetrics "github.com/guherbozdogan/kit/metrics"
+	"github.com/guherbozdogan/kit/metrics/internal/lv"
+)
+
+// Counter implements Counter, via an OpenTelemetry Float64Counter.
+type Counter struct {
+	c   metric.Float64Counter
+	lvs lv.LabelValues
+}
+
+// NewCounterFrom creates a Float64Counter with the meter, and returns a usable
+// Counter object.
+func NewCounterFrom(meter metric.Meter, name, description string) (*Counter, error) {
+	c, err := meter.Float64Counter(name, metric.WithDescription(description))
+	if err != nil {
+		return nil, err
+	}
+	return NewCounter(c), nil
+}
+
+// NewCounter wraps the Float64Counter and returns a usable Counter object.
+func NewCounter(c metric.Float64Counter) *Counter {
+	return &Counter{
+		c: c,
+	}
+}
+
+// With implements Counter.
+func (c *Counter) With(labelValues ...string) kitmetrics.Counter {
+	return &Counter{
+		c:   c.c,
+		lvs: c.lvs.With(labelValues...),
+	}
+}
+
+// Add implements Counter.
+func (c *Counter) Add(delta float64) {
+	c.c.Add(context.Background(), delta, makeAttributes(c.lvs))
+}
+
+// Gauge implements Gauge, via an OpenTelemetry Float64Gauge. Since
+// OpenTelemetry gauges can only be set, the current value of each label set is
+// tracked to support Add.
+type Gauge struct {
+	g      metric.Float64Gauge
+	lvs    lv.LabelValues
+	values *gaugeValues
+}
+
+type gaugeValues struct {
+	mtx sync.Mutex
+	m   map[string]float64
+}
+
+// NewGaugeFrom creates a Float64Gauge with the meter, and returns a usable
+// Gauge object.
+func NewGaugeFrom(meter metric.Meter, name, description string) (*Gauge, error) {
+	g, err := meter.Float64Gauge(name, metric.WithDescription(description))
+	if err != nil {
+		return nil, err
+	}
+	return NewGauge(g), nil
+}
+
+// NewGauge wraps the Float64Gauge and returns a usable Gauge object.
+func NewGauge(g metric.Float64Gauge) *Gauge {
+	return &Gauge{
+		g:      g,
+		values: &gaugeValues{m: map[string]float64{}},
+	}
+}
+
+// With implements Gauge.
+func (g *Gauge) With(labelValues ...string) kitmetrics.Gauge {
+	return &Gauge{
+		g:      g.g,
+		lvs:    g.lvs.With(labelValues...),
+		values: g.values,
+	}
+}
+
+// Set implements Gauge.
+func (g *Gauge) Set(value float64) {
+	g.update(func(float64) float64 { return value })
+}
+
+// Add implements Gauge.
+func (g *Gauge) Add(delta float64) {
+	g.update(func(value float64) float64 { return value + delta })
+}
+
+func (g *Gauge) update(f func(float64) float64) {
+	key := strings.Join(g.lvs, "\x00")
+	g.values.mtx.Lock()
+	defer g.values.mtx.Unlock()
+	value := f(g.values.m[key])
+	g.values.m[key] = value
+	g.g.Record(context.Background(), value, makeAttributes(g.lvs))
+}
+
+// Histogram implements Histogram, via an OpenTelemetry Float64Histogram.
+type Histogram struct {
+	h   metric.Float64Histogram
+	lvs lv.LabelValues
+}
+
+// NewHistogramFrom creates a Float64Histogram with the meter, and returns a
+// usable Histogram object. Options such as explicit bucket boundaries can be
+// passed to the meter.
+func NewHistogramFrom(meter metric.Meter, name, description string, options ...metric.Float64HistogramOption) (*Histogram, error) {
+	h, err := meter.Float64Histogram(name, append([]metric.Float64HistogramOption{metric.WithDescription(description)}, options...)...)
+	if err != nil {
+		return nil, err
+	}
+	return NewHistogram(h), nil
+}
+
+// NewHistogram wraps the Float64Histogram and returns a usable Histogram
+// object.
+func NewHistogram(h metric.Float64Histogram) *Histogram {
+	return &Histogram{
+		h: h,
+	}
+}
+
+// With implements Histogram.
+func (h *Histogram) With(labelValues ...string) kitmetrics.Histogram {
+	return &Histogram{
+		h:   h.h,
+		lvs: h.lvs.With(labelValues...),
+	}
+}
+
+// Observe implements Histogram.
+func (h *Histogram) Observe(value float64) {
+	h.h.Record(context.Background(), value, makeAttributes(h.lvs))
+}
+
+func makeAttributes(lvs lv.LabelValues) metric.MeasurementOption {
+	attrs := make([]attribute.KeyValue, 0, len(lvs)/2)
+	for i := 0; i < len(lvs); i += 2 {
+		attrs = append(attrs, attribute.String(lvs[i], lvs[i+1]))
+	}
+	return metric.WithAttributes(attrs...)
+}
@@ -0,0 +1,91 @@
+package opentelemetry
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func newMeter() (*sdkmetric.MeterProvider, *sdkmetric.ManualReader) {
+	reader := sdkmetric.NewManualReader()
+	return sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)), reader
+}
+
+func collect(t *testing.T, reader *sdkmetric.ManualReader) metricdata.Aggregation {
+	var rm metricdata.ResourceMetrics
+	if err := reader.Collect(context.Background(), &rm); err != nil {
+		t.Fatal(err)
+	}
+	if len(rm.ScopeMetrics) != 1 || len(rm.ScopeMetrics[0].Metrics) != 1 {
+		t.Fatalf("want 1 metric, have %v", rm.ScopeMetrics)
+	}
+	return rm.ScopeMetrics[0].Metrics[0].Data
+}
+
+func TestCounter(t *testing.T) {
+	provider, reader := newMeter()
+	counter, err := NewCounterFrom(provider.Meter("test"), "requests", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	counter.With("method", "GET").Add(1)
+	counter.With("method", "GET").Add(2)
+	counter.With("method", "POST").Add(4)
+
+	sum := collect(t, reader).(metricdata.Sum[float64])
+	want := map[string]float64{"GET": 3, "POST": 4}
+	for _, dp := range sum.DataPoints {
+		method, _ := dp.Attributes.Value(attribute.Key("method"))
+		if want, have := want[method.AsString()], dp.Value; want != have {
+			t.Errorf("%s: want %f, have %f", method.AsString(), want, have)
+		}
+	}
+}
+
+func TestGauge(t *testing.T) {
+	provider, reader := newMeter()
+	gauge, err := NewGaugeFrom(provider.Meter("test"), "depth", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gauge.With("queue", "a").Set(5)
+	gauge.With("queue", "a").Add(-2)
+	gauge.With("queue", "b").Add(1)
+
+	g := collect(t, reader).(metricdata.Gauge[float64])
+	want := map[string]float64{"a": 3, "b": 1}
+	if len(g.DataPoints) != len(want) {
+		t.Fatalf("want %d data points, have %d", len(want), len(g.DataPoints))
+	}
+	for _, dp := range g.DataPoints {
+		queue, _ := dp.Attributes.Value(attribute.Key("queue"))
+		if want, have := want[queue.AsString()], dp.Value; want != have {
+			t.Errorf("%s: want %f, have %f", queue.AsString(), want, have)
+		}
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	provider, reader := newMeter()
+	histogram, err := NewHistogramFrom(provider.Meter("test"), "latency", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range []float64{1, 2, 3} {
+		histogram.With("method", "GET").Observe(v)
+	}
+
+	h := collect(t, reader).(metricdata.Histogram[float64])
+	if want, have := 1, len(h.DataPoints); want != have {
+		t.Fatalf("want %d, have %d", want, have)
+	}
+	if want, have := uint64(3), h.DataPoints[0].Count; want != have {
+		t.Errorf("want %d, have %d", want, have)
+	}
+	if want, have := 6.0, h.DataPoints[0].Sum; want != have {
+		t.Errorf("want %f, have %f", want, have)
+	}
+}
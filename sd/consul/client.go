@@ -16,6 +16,32 @@ type Client interface {
 	Service(service, tag string, passingOnly bool, queryOpts *consul.QueryOptions) ([]*consul.ServiceEntry, *consul.QueryMeta, error)
 }
 
+// TTLClient is implemented by clients that can update TTL checks, as required
+// by RegistrarTTL. The client returned by NewClient implements it.
+type TTLClient interface {
+	// UpdateTTL sets the status and output of a TTL check registered with the
+	// local agent.
+	UpdateTTL(checkID, output, status string) error
+}
+
+// MultipleTagsClient is implemented by clients that can filter services by
+// more than one tag in Consul. The subscriber uses it if the client implements
+// it, and filters the tags itself otherwise. The client returned by NewClient
+// implements it.
+type MultipleTagsClient interface {
+	// ServiceMultipleTags is the same as Service, but only returns entries
+	// which carry all of the given tags.
+	ServiceMultipleTags(service string, tags []string, passingOnly bool, queryOpts *consul.QueryOptions) ([]*consul.ServiceEntry, *consul.QueryMeta, error)
+}
+
+// PreparedQueryClient is implemented by clients that can execute prepared
+// queries, as required by SubscriberPreparedQuery. The client returned by
+// NewClient implements it.
+type PreparedQueryClient interface {
+	// ExecuteQuery executes a prepared query by ID or name.
+	ExecuteQuery(queryIDOrName string, queryOpts *consul.QueryOptions) (*consul.PreparedQueryExecuteResponse, *consul.QueryMeta, error)
+}
+
 type client struct {
 	consul *consul.Client
 }
@@ -26,6 +52,12 @@ func NewClient(c *consul.Client) Client {
 	return &client{consul: c}
 }
 
+var (
+	_ TTLClient           = &client{}
+	_ MultipleTagsClient  = &client{}
+	_ PreparedQueryClient = &client{}
+)
+
 func (c *client) Register(r *consul.AgentServiceRegistration) error {
 	return c.consul.Agent().ServiceRegister(r)
 }
@@ -34,6 +66,18 @@ func (c *client) Deregister(r *consul.AgentServiceRegistration) error {
 	return c.consul.Agent().ServiceDeregister(r.ID)
 }
 
+func (c *client) UpdateTTL(checkID, output, status string) error {
+	return c.consul.Agent().UpdateTTL(checkID, output, status)
+}
+
 func (c *client) Service(service, tag string, passingOnly bool, queryOpts *consul.QueryOptions) ([]*consul.ServiceEntry, *consul.QueryMeta, error) {
 	return c.consul.Health().Service(service, tag, passingOnly, queryOpts)
 }
+
+func (c *client) ServiceMultipleTags(service string, tags []string, passingOnly bool, queryOpts *consul.QueryOptions) ([]*consul.ServiceEntry, *consul.QueryMeta, error) {
+	return c.consul.Health().ServiceMultipleTags(service, tags, passingOnly, queryOpts)
+}
+
+func (c *client) ExecuteQuery(queryIDOrName string, queryOpts *consul.QueryOptions) (*consul.PreparedQueryExecuteResponse, *consul.QueryMeta, error) {
+	return c.consul.PreparedQuery().Execute(queryIDOrName, queryOpts)
+}
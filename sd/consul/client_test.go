@@ -5,6 +5,7 @@ import (
 	"errors"
 	"io"
 	"reflect"
+	"sync"
 	"testing"
 
 	stdconsul "github.com/hashicorp/consul/api"
@@ -57,33 +58,60 @@ func TestClientRegistration(t *testing.T) {
 }
 
 type testClient struct {
+	queries map[string][]stdconsul.ServiceEntry
+
+	mtx     sync.Mutex
 	entries []*stdconsul.ServiceEntry
+	checks  map[string]string // check ID to status
+	opts    *stdconsul.QueryOptions
+
+	// beforeUpdateTTL, if set, is called at the start of UpdateTTL, and
+	// fails it if it returns an error.
+	beforeUpdateTTL func() error
 }
 
 func newTestClient(entries []*stdconsul.ServiceEntry) *testClient {
 	return &testClient{
 		entries: entries,
+		checks:  map[string]string{},
 	}
 }
 
-var _ Client = &testClient{}
+var (
+	_ Client              = &testClient{}
+	_ TTLClient           = &testClient{}
+	_ MultipleTagsClient  = &testClient{}
+	_ PreparedQueryClient = &testClient{}
+)
+
+func (c *testClient) Service(service, tag string, passingOnly bool, opts *stdconsul.QueryOptions) ([]*stdconsul.ServiceEntry, *stdconsul.QueryMeta, error) {
+	var tags []string
+	if tag != "" {
+		tags = []string{tag}
+	}
+	return c.ServiceMultipleTags(service, tags, passingOnly, opts)
+}
+
+func (c *testClient) ServiceMultipleTags(service string, tags []string, _ bool, opts *stdconsul.QueryOptions) ([]*stdconsul.ServiceEntry, *stdconsul.QueryMeta, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.opts = opts
 
-func (c *testClient) Service(service, tag string, _ bool, opts *stdconsul.QueryOptions) ([]*stdconsul.ServiceEntry, *stdconsul.QueryMeta, error) {
 	var results []*stdconsul.ServiceEntry
 
+ENTRIES:
 	for _, entry := range c.entries {
 		if entry.Service.Service != service {
 			continue
 		}
-		if tag != "" {
-			tagMap := map[string]struct{}{}
-
-			for _, t := range entry.Service.Tags {
-				tagMap[t] = struct{}{}
-			}
 
+		tagMap := map[string]struct{}{}
+		for _, t := range entry.Service.Tags {
+			tagMap[t] = struct{}{}
+		}
+		for _, tag := range tags {
 			if _, ok := tagMap[tag]; !ok {
-				continue
+				continue ENTRIES
 			}
 		}
 
@@ -93,7 +121,28 @@ func (c *testClient) Service(service, tag string, _ bool, opts *stdconsul.QueryO
 	return results, &stdconsul.QueryMeta{}, nil
 }
 
+func (c *testClient) ExecuteQuery(queryIDOrName string, opts *stdconsul.QueryOptions) (*stdconsul.PreparedQueryExecuteResponse, *stdconsul.QueryMeta, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.opts = opts
+
+	nodes, ok := c.queries[queryIDOrName]
+	if !ok {
+		return nil, nil, errors.New("query not found")
+	}
+	return &stdconsul.PreparedQueryExecuteResponse{Nodes: nodes}, &stdconsul.QueryMeta{}, nil
+}
+
+func (c *testClient) lastOpts() *stdconsul.QueryOptions {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.opts
+}
+
 func (c *testClient) Register(r *stdconsul.AgentServiceRegistration) error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
 	toAdd := registration2entry(r)
 
 	for _, entry := range c.entries {
@@ -103,10 +152,18 @@ func (c *testClient) Register(r *stdconsul.AgentServiceRegistration) error {
 	}
 
 	c.entries = append(c.entries, toAdd)
+	for _, check := range r.Checks {
+		if check.TTL != "" {
+			c.checks[check.CheckID] = stdconsul.HealthCritical
+		}
+	}
 	return nil
 }
 
 func (c *testClient) Deregister(r *stdconsul.AgentServiceRegistration) error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
 	toDelete := registration2entry(r)
 
 	var newEntries []*stdconsul.ServiceEntry
@@ -121,9 +178,50 @@ func (c *testClient) Deregister(r *stdconsul.AgentServiceRegistration) error {
 	}
 
 	c.entries = newEntries
+	for _, check := range r.Checks {
+		delete(c.checks, check.CheckID)
+	}
 	return nil
 }
 
+func (c *testClient) UpdateTTL(checkID, output, status string) error {
+	if c.beforeUpdateTTL != nil {
+		if err := c.beforeUpdateTTL(); err != nil {
+			return err
+		}
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if _, ok := c.checks[checkID]; !ok {
+		return errors.New("unknown check")
+	}
+	c.checks[checkID] = status
+	return nil
+}
+
+// restart simulates a restart of the local agent, which forgets all services
+// and checks registered with it.
+func (c *testClient) restart() {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	c.entries = nil
+	c.checks = map[string]string{}
+}
+
+func (c *testClient) numEntries() int {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return len(c.entries)
+}
+
+func (c *testClient) checkStatus(checkID string) string {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+	return c.checks[checkID]
+}
+
 func registration2entry(r *stdconsul.AgentServiceRegistration) *stdconsul.ServiceEntry {
 	return &stdconsul.ServiceEntry{
 		Node: &stdconsul.Node{
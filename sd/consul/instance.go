@@ -0,0 +1,87 @@
+package consul
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	consul "github.com/hashicorp/consul/api"
+)
+
+// InstanceFunc converts a Consul service entry to the instance string that's
+// passed to the subscriber's factory. Instance strings identify endpoints in
+// the subscriber's cache, so equal entries must yield equal strings.
+type InstanceFunc func(*consul.ServiceEntry) string
+
+// AddressInstance yields host:port instance strings. The service address is
+// used if set; otherwise, the node address is used.
+func AddressInstance(entry *consul.ServiceEntry) string {
+	return fmt.Sprintf("%s:%d", entryAddress(entry), entry.Service.Port)
+}
+
+// MetadataInstance yields instance strings which carry the service's tags and
+// metadata in addition to its address, so that factories can choose e.g. a
+// protocol or API version per instance. The format is host:port, optionally
+// followed by a query string; use ParseInstance to decode it.
+func MetadataInstance(entry *consul.ServiceEntry) string {
+	v := url.Values{}
+	for _, tag := range entry.Service.Tags {
+		v.Add(tagKey, tag)
+	}
+	for key, value := range entry.Service.Meta {
+		v.Set(metaPrefix+key, value)
+	}
+	instance := AddressInstance(entry)
+	if len(v) > 0 {
+		instance += "?" + v.Encode()
+	}
+	return instance
+}
+
+const (
+	tagKey     = "tag"
+	metaPrefix = "meta."
+)
+
+// Instance is the decoded form of an instance string yielded by
+// MetadataInstance.
+type Instance struct {
+	Address string // host:port
+	Tags    []string
+	Meta    map[string]string
+}
+
+// ParseInstance decodes an instance string yielded by MetadataInstance. Plain
+// host:port instance strings are also accepted.
+func ParseInstance(instance string) (Instance, error) {
+	var (
+		address = instance
+		query   string
+	)
+	if i := strings.IndexByte(instance, '?'); i >= 0 {
+		address, query = instance[:i], instance[i+1:]
+	}
+	v, err := url.ParseQuery(query)
+	if err != nil {
+		return Instance{}, err
+	}
+
+	inst := Instance{
+		Address: address,
+		Tags:    v[tagKey],
+		Meta:    map[string]string{},
+	}
+	for key := range v {
+		if strings.HasPrefix(key, metaPrefix) {
+			inst.Meta[strings.TrimPrefix(key, metaPrefix)] = v.Get(key)
+		}
+	}
+	return inst, nil
+}
+
+func entryAddress(entry *consul.ServiceEntry) string {
+	if entry.Service.Address != "" {
+		return entry.Service.Address
+	}
+	return entry.Node.Address
+}
@@ -1,7 +1,10 @@
 package consul
 
 import (
+	"errors"
 	"fmt"
+	"sync"
+	"time"
 
 	stdconsul "github.com/hashicorp/consul/api"
 
@@ -11,18 +14,87 @@ import (
 // Registrar registers service instance liveness information to Consul.
 type Registrar struct {
 	client       Client
+	ttlClient    TTLClient
 	registration *stdconsul.AgentServiceRegistration
 	logger       log.Logger
+	ttl          *TTLOption
+
+	quitmtx sync.Mutex
+	quit    chan struct{}
+	done    chan struct{}
+}
+
+// RegistrarOption sets an optional parameter for registrars.
+type RegistrarOption func(*Registrar)
+
+// RegistrarTTL adds a TTL check to the registration. After Register, a loop
+// goroutine reports the result of the TTL option's health func to the check
+// every heartbeat, and re-registers the service if the agent no longer knows
+// about the check, e.g. after the agent restarted. The client must implement
+// TTLClient; otherwise, the error is logged and no check is added.
+func RegistrarTTL(ttl *TTLOption) RegistrarOption {
+	return func(p *Registrar) { p.ttl = ttl }
+}
+
+// TTLOption configures the TTL check maintained by a registrar.
+type TTLOption struct {
+	heartbeat time.Duration // e.g. time.Second * 3
+	ttl       time.Duration // e.g. time.Second * 10
+	health    func() error
 }
 
+// NewTTLOption returns a TTLOption that contains proper TTL settings. Heartbeat
+// is how often the check is updated; its value should be at least 500ms. TTL is
+// how long Consul waits for an update before marking the check critical; its
+// value should be significantly greater than heartbeat. Health is invoked on
+// every heartbeat: the check passes if it returns nil, and is critical with the
+// error as output otherwise. A nil health func always passes.
+//
+// Good default values might be 3s heartbeat, 10s TTL.
+func NewTTLOption(heartbeat, ttl time.Duration, health func() error) *TTLOption {
+	if heartbeat <= minHeartBeatTime {
+		heartbeat = minHeartBeatTime
+	}
+	if ttl <= heartbeat {
+		ttl = 3 * heartbeat
+	}
+	if health == nil {
+		health = func() error { return nil }
+	}
+	return &TTLOption{
+		heartbeat: heartbeat,
+		ttl:       ttl,
+		health:    health,
+	}
+}
+
+const minHeartBeatTime = 500 * time.Millisecond
+
+// ErrTTLUnsupported is logged when RegistrarTTL is used with a client that
+// doesn't implement TTLClient.
+var ErrTTLUnsupported = errors.New("client doesn't support TTL checks")
+
 // NewRegistrar returns a Consul Registrar acting on the provided catalog
 // registration.
-func NewRegistrar(client Client, r *stdconsul.AgentServiceRegistration, logger log.Logger) *Registrar {
-	return &Registrar{
+func NewRegistrar(client Client, r *stdconsul.AgentServiceRegistration, logger log.Logger, options ...RegistrarOption) *Registrar {
+	p := &Registrar{
 		client:       client,
 		registration: r,
 		logger:       log.With(logger, "service", r.Name, "tags", fmt.Sprint(r.Tags), "address", r.Address),
 	}
+	for _, option := range options {
+		option(p)
+	}
+	if p.ttl != nil {
+		if c, ok := client.(TTLClient); ok {
+			p.ttlClient = c
+			p.registration = withTTLCheck(r, p.ttl.ttl)
+		} else {
+			p.logger.Log("err", ErrTTLUnsupported)
+			p.ttl = nil
+		}
+	}
+	return p
 }
 
 // Register implements sd.Registrar interface.
@@ -32,13 +104,101 @@ func (p *Registrar) Register() {
 	} else {
 		p.logger.Log("action", "register")
 	}
+	if p.ttl == nil {
+		return
+	}
+
+	p.quitmtx.Lock()
+	defer p.quitmtx.Unlock()
+	if p.quit != nil {
+		return // already running
+	}
+	p.quit = make(chan struct{})
+	p.done = make(chan struct{})
+	p.heartbeat(p.quit)
+	go p.loop(p.quit, p.done)
 }
 
-// Deregister implements sd.Registrar interface.
+// Deregister implements sd.Registrar interface. It stops the TTL loop and
+// waits for it to return before deregistering, so that a heartbeat can't
+// register the service again.
 func (p *Registrar) Deregister() {
+	p.quitmtx.Lock()
+	if p.quit != nil {
+		close(p.quit)
+		<-p.done
+		p.quit, p.done = nil, nil
+	}
+	p.quitmtx.Unlock()
+
 	if err := p.client.Deregister(p.registration); err != nil {
 		p.logger.Log("err", err)
 	} else {
 		p.logger.Log("action", "deregister")
 	}
 }
+
+func (p *Registrar) loop(quit, done chan struct{}) {
+	defer close(done)
+	tick := time.NewTicker(p.ttl.heartbeat)
+	defer tick.Stop()
+	for {
+		select {
+		case <-tick.C:
+			p.heartbeat(quit)
+		case <-quit:
+			return
+		}
+	}
+}
+
+// heartbeat reports the current health to the TTL check. If the update fails,
+// the agent has most likely lost the registration, so the service is
+// registered again, unless the registrar is quitting.
+func (p *Registrar) heartbeat(quit chan struct{}) {
+	status, output := stdconsul.HealthPassing, ""
+	if err := p.ttl.health(); err != nil {
+		status, output = stdconsul.HealthCritical, err.Error()
+	}
+
+	checkID := ttlCheckID(p.registration)
+	err := p.ttlClient.UpdateTTL(checkID, output, status)
+	if err == nil {
+		return
+	}
+	p.logger.Log("check", checkID, "err", err)
+
+	select {
+	case <-quit:
+		return // deregistering
+	default:
+	}
+	if err := p.client.Register(p.registration); err != nil {
+		p.logger.Log("err", err)
+		return
+	}
+	p.logger.Log("action", "reregister")
+
+	if err := p.ttlClient.UpdateTTL(checkID, output, status); err != nil {
+		p.logger.Log("check", checkID, "err", err)
+	}
+}
+
+// withTTLCheck returns a copy of the registration with an additional TTL check.
+func withTTLCheck(r *stdconsul.AgentServiceRegistration, ttl time.Duration) *stdconsul.AgentServiceRegistration {
+	registration := *r
+	registration.Checks = append(stdconsul.AgentServiceChecks{}, r.Checks...)
+	registration.Checks = append(registration.Checks, &stdconsul.AgentServiceCheck{
+		CheckID: ttlCheckID(r),
+		TTL:     ttl.String(),
+	})
+	return &registration
+}
+
+func ttlCheckID(r *stdconsul.AgentServiceRegistration) string {
+	id := r.ID
+	if id == "" {
+		id = r.Name // Consul defaults the service ID to its name
+	}
+	return "service:" + id + ":ttl"
+}
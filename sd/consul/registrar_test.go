@@ -1,7 +1,9 @@
 package consul
 
 import (
+	"errors"
 	"testing"
+	"time"
 
 	stdconsul "github.com/hashicorp/consul/api"
 
@@ -25,3 +27,117 @@ func TestRegistrar(t *testing.T) {
 		t.Errorf("want %d, have %d", want, have)
 	}
 }
+
+func TestRegistrarTTL(t *testing.T) {
+	var (
+		client = newTestClient([]*stdconsul.ServiceEntry{})
+		health error
+	)
+	ttl := NewTTLOption(time.Hour, 2*time.Hour, func() error { return health })
+
+	// The loop never ticks during the test; heartbeats are triggered directly.
+	p := NewRegistrar(client, testRegistration, log.NewNopLogger(), RegistrarTTL(ttl))
+	checkID := ttlCheckID(testRegistration)
+
+	p.Register()
+	if want, have := stdconsul.HealthPassing, client.checkStatus(checkID); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+
+	health = errors.New("unhealthy")
+	p.heartbeat(p.quit)
+	if want, have := stdconsul.HealthCritical, client.checkStatus(checkID); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+
+	// After an agent restart, the registrar registers again.
+	health = nil
+	client.restart()
+	p.heartbeat(p.quit)
+	if want, have := 1, client.numEntries(); want != have {
+		t.Errorf("want %d, have %d", want, have)
+	}
+	if want, have := stdconsul.HealthPassing, client.checkStatus(checkID); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+
+	p.Deregister()
+	if want, have := 0, client.numEntries(); want != have {
+		t.Errorf("want %d, have %d", want, have)
+	}
+}
+
+func TestRegistrarTTLDeregisterDuringHeartbeat(t *testing.T) {
+	var (
+		client   = newTestClient([]*stdconsul.ServiceEntry{})
+		armed    = make(chan struct{})
+		inUpdate = make(chan struct{})
+		release  = make(chan struct{})
+	)
+	client.beforeUpdateTTL = func() error {
+		select {
+		case <-armed:
+		default:
+			return nil
+		}
+		// Fail the first heartbeat after arming, once Deregister is waiting
+		// for the loop.
+		select {
+		case inUpdate <- struct{}{}:
+			<-release
+			return errors.New("agent unavailable")
+		default:
+			return nil
+		}
+	}
+
+	ttl := NewTTLOption(0, 0, nil)
+	ttl.heartbeat = time.Millisecond // below the minimum, for tests only
+	p := NewRegistrar(client, testRegistration, log.NewNopLogger(), RegistrarTTL(ttl))
+	p.Register()
+	quit := p.quit
+
+	close(armed)
+	<-inUpdate // the loop is in a heartbeat
+
+	deregistered := make(chan struct{})
+	go func() {
+		p.Deregister()
+		close(deregistered)
+	}()
+	<-quit // Deregister is waiting for the loop
+	close(release)
+	<-deregistered
+
+	if want, have := 0, client.numEntries(); want != have {
+		t.Errorf("want %d, have %d", want, have)
+	}
+}
+
+func TestRegistrarTTLUnsupported(t *testing.T) {
+	client := newTestClient([]*stdconsul.ServiceEntry{})
+	ttl := NewTTLOption(time.Hour, 2*time.Hour, nil)
+
+	// A client which only implements Client can't update the check, so none
+	// is registered.
+	p := NewRegistrar(struct{ Client }{client}, testRegistration, log.NewNopLogger(), RegistrarTTL(ttl))
+	p.Register()
+	if want, have := 1, client.numEntries(); want != have {
+		t.Errorf("want %d, have %d", want, have)
+	}
+	if want, have := "", client.checkStatus(ttlCheckID(testRegistration)); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+
+	p.Deregister()
+	if want, have := 0, client.numEntries(); want != have {
+		t.Errorf("want %d, have %d", want, have)
+	}
+}
+
+func TestRegistrarTTLLeavesRegistrationUntouched(t *testing.T) {
+	NewRegistrar(newTestClient(nil), testRegistration, log.NewNopLogger(), RegistrarTTL(NewTTLOption(0, 0, nil)))
+	if want, have := 0, len(testRegistration.Checks); want != have {
+		t.Errorf("want %d, have %d", want, have)
+	}
+}
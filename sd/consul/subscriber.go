@@ -1,8 +1,10 @@
 package consul
 
 import (
+	"errors"
 	"fmt"
 	"io"
+	"time"
 
 	consul "github.com/hashicorp/consul/api"
 
@@ -14,34 +16,99 @@ import (
 
 const defaultIndex = 0
 
+// DefaultQueryInterval is the interval at which prepared queries are
+// re-executed if SubscriberPreparedQuery is given a non-positive interval.
+const DefaultQueryInterval = 10 * time.Second
+
+// ErrPreparedQueryUnsupported is returned when SubscriberPreparedQuery is used
+// with a client that doesn't implement PreparedQueryClient.
+var ErrPreparedQueryUnsupported = errors.New("client doesn't support prepared queries")
+
 // Subscriber yields endpoints for a service in Consul. Updates to the service
 // are watched and will update the Subscriber endpoints.
 type Subscriber struct {
-	cache       *cache.Cache
-	client      Client
-	logger      log.Logger
-	service     string
-	tags        []string
-	passingOnly bool
-	endpointsc  chan []endpoint.Endpoint
-	quitc       chan struct{}
+	cache         *cache.Cache
+	client        Client
+	logger        log.Logger
+	service       string
+	tags          []string
+	passingOnly   bool
+	datacenter    string
+	nodeMeta      map[string]string
+	waitTime      time.Duration
+	query         string
+	queryInterval time.Duration
+	instance      InstanceFunc
+	endpointsc    chan []endpoint.Endpoint
+	quitc         chan struct{}
 }
 
 var _ sd.Subscriber = &Subscriber{}
 
+// SubscriberOption sets an optional parameter for subscribers.
+type SubscriberOption func(*Subscriber)
+
+// SubscriberDatacenter queries the given datacenter instead of the datacenter
+// of the agent the client is connected to.
+func SubscriberDatacenter(dc string) SubscriberOption {
+	return func(s *Subscriber) { s.datacenter = dc }
+}
+
+// SubscriberNodeMeta only returns instances running on nodes which carry all of
+// the given node metadata key/value pairs.
+func SubscriberNodeMeta(meta map[string]string) SubscriberOption {
+	return func(s *Subscriber) { s.nodeMeta = meta }
+}
+
+// SubscriberWaitTime bounds the duration of each blocking query. By default,
+// the Consul agent's default wait time is used.
+func SubscriberWaitTime(d time.Duration) SubscriberOption {
+	return func(s *Subscriber) { s.waitTime = d }
+}
+
+// SubscriberPreparedQuery makes the subscriber execute the named prepared query
+// instead of querying the health endpoint for the service. Prepared queries
+// don't support blocking, so the query is re-executed every interval, or every
+// DefaultQueryInterval if the interval isn't positive. The client must
+// implement PreparedQueryClient. The service, tags and passingOnly parameters
+// given to NewSubscriber are ignored, as they're part of the query definition.
+func SubscriberPreparedQuery(queryIDOrName string, interval time.Duration) SubscriberOption {
+	return func(s *Subscriber) {
+		s.query = queryIDOrName
+		s.queryInterval = interval
+	}
+}
+
+// SubscriberInstance sets the function that converts service entries to the
+// instance strings passed to the factory. By default, AddressInstance is used.
+func SubscriberInstance(f InstanceFunc) SubscriberOption {
+	return func(s *Subscriber) { s.instance = f }
+}
+
 // NewSubscriber returns a Consul subscriber which returns endpoints for the
 // requested service. It only returns instances for which all of the passed tags
 // are present.
-func NewSubscriber(client Client, factory sd.Factory, logger log.Logger, service string, tags []string, passingOnly bool) *Subscriber {
+func NewSubscriber(client Client, factory sd.Factory, logger log.Logger, service string, tags []string, passingOnly bool, options ...SubscriberOption) *Subscriber {
 	s := &Subscriber{
 		cache:       cache.New(factory, logger),
 		client:      client,
-		logger:      log.With(logger, "service", service, "tags", fmt.Sprint(tags)),
 		service:     service,
 		tags:        tags,
 		passingOnly: passingOnly,
+		instance:    AddressInstance,
 		quitc:       make(chan struct{}),
 	}
+	for _, option := range options {
+		option(s)
+	}
+	if s.query != "" && s.queryInterval <= 0 {
+		s.queryInterval = DefaultQueryInterval
+	}
+	if s.query != "" {
+		s.logger = log.With(logger, "query", s.query)
+	} else {
+		s.logger = log.With(logger, "service", service, "tags", fmt.Sprint(tags))
+	}
 
 	instances, index, err := s.getInstances(defaultIndex, nil)
 	if err == nil {
@@ -71,6 +138,14 @@ func (s *Subscriber) loop(lastIndex uint64) {
 		err       error
 	)
 	for {
+		if s.query != "" {
+			select {
+			case <-time.After(s.queryInterval):
+			case <-s.quitc:
+				return
+			}
+		}
+
 		instances, lastIndex, err = s.getInstances(lastIndex, s.quitc)
 		switch {
 		case err == io.EOF:
@@ -84,17 +159,6 @@ func (s *Subscriber) loop(lastIndex uint64) {
 }
 
 func (s *Subscriber) getInstances(lastIndex uint64, interruptc chan struct{}) ([]string, uint64, error) {
-	tag := ""
-	if len(s.tags) > 0 {
-		tag = s.tags[0]
-	}
-
-	// Consul doesn't support more than one tag in its service query method.
-	// https://github.com/hashicorp/consul/issues/294
-	// Hashi suggest prepared queries, but they don't support blocking.
-	// https://www.consul.io/docs/agent/http/query.html#execute
-	// If we want blocking for efficiency, we must filter tags manually.
-
 	type response struct {
 		instances []string
 		index     uint64
@@ -103,21 +167,22 @@ func (s *Subscriber) getInstances(lastIndex uint64, interruptc chan struct{}) ([
 	var (
 		errc = make(chan error, 1)
 		resc = make(chan response, 1)
+		opts = &consul.QueryOptions{
+			Datacenter: s.datacenter,
+			NodeMeta:   s.nodeMeta,
+			WaitIndex:  lastIndex,
+			WaitTime:   s.waitTime,
+		}
 	)
 
 	go func() {
-		entries, meta, err := s.client.Service(s.service, tag, s.passingOnly, &consul.QueryOptions{
-			WaitIndex: lastIndex,
-		})
+		entries, meta, err := s.getEntries(opts)
 		if err != nil {
 			errc <- err
 			return
 		}
-		if len(s.tags) > 1 {
-			entries = filterEntries(entries, s.tags[1:]...)
-		}
 		resc <- response{
-			instances: makeInstances(entries),
+			instances: makeInstances(entries, s.instance),
 			index:     meta.LastIndex,
 		}
 	}()
@@ -132,6 +197,45 @@ func (s *Subscriber) getInstances(lastIndex uint64, interruptc chan struct{}) ([
 	}
 }
 
+func (s *Subscriber) getEntries(opts *consul.QueryOptions) ([]*consul.ServiceEntry, *consul.QueryMeta, error) {
+	switch {
+	case s.query != "":
+		c, ok := s.client.(PreparedQueryClient)
+		if !ok {
+			return nil, nil, ErrPreparedQueryUnsupported
+		}
+		// Prepared queries ignore the wait index; they never block.
+		res, meta, err := c.ExecuteQuery(s.query, opts)
+		if err != nil {
+			return nil, nil, err
+		}
+		entries := make([]*consul.ServiceEntry, len(res.Nodes))
+		for i := range res.Nodes {
+			entries[i] = &res.Nodes[i]
+		}
+		return entries, meta, nil
+
+	case len(s.tags) > 1:
+		if c, ok := s.client.(MultipleTagsClient); ok {
+			return c.ServiceMultipleTags(s.service, s.tags, s.passingOnly, opts)
+		}
+		// The client can only filter by one tag, so we filter the others
+		// manually.
+		entries, meta, err := s.client.Service(s.service, s.tags[0], s.passingOnly, opts)
+		if err != nil {
+			return nil, nil, err
+		}
+		return filterEntries(entries, s.tags[1:]...), meta, nil
+
+	default:
+		tag := ""
+		if len(s.tags) > 0 {
+			tag = s.tags[0]
+		}
+		return s.client.Service(s.service, tag, s.passingOnly, opts)
+	}
+}
+
 func filterEntries(entries []*consul.ServiceEntry, tags ...string) []*consul.ServiceEntry {
 	var es []*consul.ServiceEntry
 
@@ -153,14 +257,10 @@ ENTRIES:
 	return es
 }
 
-func makeInstances(entries []*consul.ServiceEntry) []string {
+func makeInstances(entries []*consul.ServiceEntry, instance InstanceFunc) []string {
 	instances := make([]string, len(entries))
 	for i, entry := range entries {
-		addr := entry.Node.Address
-		if entry.Service.Address != "" {
-			addr = entry.Service.Address
-		}
-		instances[i] = fmt.Sprintf("%s:%d", addr, entry.Service.Port)
+		instances[i] = instance(entry)
 	}
 	return instances
 }
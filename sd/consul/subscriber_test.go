@@ -2,7 +2,9 @@ package consul
 
 import (
 	"context"
+	"reflect"
 	"testing"
+	"time"
 
 	consul "github.com/hashicorp/consul/api"
 
@@ -23,6 +25,9 @@ var consulState = []*consul.ServiceEntry{
 				"api",
 				"v1",
 			},
+			Meta: map[string]string{
+				"protocol": "grpc",
+			},
 		},
 	},
 	{
@@ -114,6 +119,23 @@ func TestSubscriberWithTags(t *testing.T) {
 	}
 }
 
+func TestSubscriberWithTagsSingleTagClient(t *testing.T) {
+	// Hide all methods but those of Client.
+	client := struct{ Client }{newTestClient(consulState)}
+
+	s := NewSubscriber(client, testFactory, log.NewNopLogger(), "search", []string{"api", "v2"}, true)
+	defer s.Stop()
+
+	endpoints, err := s.Endpoints()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want, have := 1, len(endpoints); want != have {
+		t.Fatalf("want %d, have %d", want, have)
+	}
+}
+
 func TestSubscriberAddressOverride(t *testing.T) {
 	s := NewSubscriber(newTestClient(consulState), testFactory, log.NewNopLogger(), "search", []string{"db"}, true)
 	defer s.Stop()
@@ -136,3 +158,120 @@ func TestSubscriberAddressOverride(t *testing.T) {
 		t.Errorf("want %q, have %q", want, have)
 	}
 }
+
+func TestSubscriberQueryOptions(t *testing.T) {
+	client := newTestClient(consulState)
+	s := NewSubscriber(client, testFactory, log.NewNopLogger(), "search", []string{"api"}, true,
+		SubscriberDatacenter("dc2"),
+		SubscriberNodeMeta(map[string]string{"rack": "r1"}),
+		SubscriberWaitTime(time.Minute),
+	)
+	defer s.Stop()
+
+	opts := client.lastOpts()
+	if want, have := "dc2", opts.Datacenter; want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+	if want, have := "r1", opts.NodeMeta["rack"]; want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+	if want, have := time.Minute, opts.WaitTime; want != have {
+		t.Errorf("want %v, have %v", want, have)
+	}
+}
+
+func TestSubscriberPreparedQuery(t *testing.T) {
+	client := newTestClient(nil)
+	client.queries = map[string][]consul.ServiceEntry{
+		"search-nearest": {*consulState[0], *consulState[2]},
+	}
+
+	s := NewSubscriber(client, testFactory, log.NewNopLogger(), "", nil, true,
+		SubscriberPreparedQuery("search-nearest", time.Hour),
+	)
+	defer s.Stop()
+
+	endpoints, err := s.Endpoints()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want, have := 2, len(endpoints); want != have {
+		t.Fatalf("want %d, have %d", want, have)
+	}
+}
+
+func TestSubscriberPreparedQueryInterval(t *testing.T) {
+	client := newTestClient(nil)
+	client.queries = map[string][]consul.ServiceEntry{"search-nearest": nil}
+
+	s := NewSubscriber(client, testFactory, log.NewNopLogger(), "", nil, true,
+		SubscriberPreparedQuery("search-nearest", 0),
+	)
+	defer s.Stop()
+
+	if want, have := DefaultQueryInterval, s.queryInterval; want != have {
+		t.Errorf("want %v, have %v", want, have)
+	}
+}
+
+func TestSubscriberPreparedQueryUnsupported(t *testing.T) {
+	client := struct{ Client }{newTestClient(nil)}
+
+	s := NewSubscriber(client, testFactory, log.NewNopLogger(), "", nil, true,
+		SubscriberPreparedQuery("search-nearest", time.Hour),
+	)
+	defer s.Stop()
+
+	if _, _, err := s.getEntries(&consul.QueryOptions{}); err != ErrPreparedQueryUnsupported {
+		t.Errorf("want %v, have %v", ErrPreparedQueryUnsupported, err)
+	}
+}
+
+func TestSubscriberMetadataInstance(t *testing.T) {
+	s := NewSubscriber(newTestClient(consulState), testFactory, log.NewNopLogger(), "search", []string{"api", "v1"}, true,
+		SubscriberInstance(MetadataInstance),
+	)
+	defer s.Stop()
+
+	endpoints, err := s.Endpoints()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want, have := 1, len(endpoints); want != have {
+		t.Fatalf("want %d, have %d", want, have)
+	}
+
+	response, err := endpoints[0](context.Background(), struct{}{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	instance, err := ParseInstance(response.(string))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, have := "10.0.0.0:8000", instance.Address; want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+	if want, have := []string{"api", "v1"}, instance.Tags; !reflect.DeepEqual(want, have) {
+		t.Errorf("want %v, have %v", want, have)
+	}
+	if want, have := "grpc", instance.Meta["protocol"]; want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+}
+
+func TestParseInstancePlainAddress(t *testing.T) {
+	instance, err := ParseInstance("10.0.0.1:8001")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, have := "10.0.0.1:8001", instance.Address; want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+	if want, have := 0, len(instance.Tags)+len(instance.Meta); want != have {
+		t.Errorf("want %d, have %d", want, have)
+	}
+}
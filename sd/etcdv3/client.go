@@ -0,0 +1,296 @@
+package etcdv3
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io/ioutil"
+	"sync"
+	"time"
+
+	"github.com/coreos/etcd/clientv3"
+
+	"github.com/guherbozdogan/kit/log"
+)
+
+var (
+	// ErrNoKey indicates a client method needs a key but receives none.
+	ErrNoKey = errors.New("no key provided")
+
+	// ErrNoValue indicates a client method needs a value but receives none.
+	ErrNoValue = errors.New("no value provided")
+)
+
+// Client is a wrapper around the etcd v3 client.
+type Client interface {
+	// GetEntries queries the given prefix in etcd and returns a slice
+	// containing the values of all keys found underneath that prefix.
+	GetEntries(prefix string) ([]string, error)
+
+	// WatchPrefix watches the given prefix in etcd for changes. When a change
+	// is detected, it will signal on the passed channel. Clients are expected
+	// to call GetEntries to update themselves with the latest set of complete
+	// values. WatchPrefix will always send an initial sentinel value on the
+	// channel after establishing the watch, to ensure that clients always
+	// receive the latest set of values. If the watch fails, it's established
+	// again, and the sentinel value is sent again. WatchPrefix will block until
+	// the context passed to the NewClient constructor is terminated.
+	WatchPrefix(prefix string, ch chan struct{})
+
+	// Register a service with etcd. If the service has a TTL, its key is
+	// attached to a lease which is kept alive until Deregister is called. If
+	// the lease is lost nevertheless, e.g. during a network partition longer
+	// than the TTL, a new lease is granted and the key is put again.
+	Register(s Service) error
+
+	// Deregister a service with etcd, revoking its lease if it has one.
+	Deregister(s Service) error
+}
+
+type client struct {
+	kv      clientv3.KV
+	lease   clientv3.Lease
+	watcher clientv3.Watcher
+	ctx     context.Context
+	logger  log.Logger
+	retry   time.Duration // between attempts to watch or register again
+
+	mtx    sync.Mutex
+	leases map[string]*lease // by service key
+}
+
+type lease struct {
+	id     clientv3.LeaseID   // guarded by the client's mutex
+	cancel context.CancelFunc // stops the keepalive
+	done   chan struct{}      // closed when the keepalive has stopped
+}
+
+// retryInterval is the time between attempts to watch or register again.
+const retryInterval = time.Second
+
+// ClientOptions defines options for the etcd client. All values are optional.
+// If any duration is not specified, a default of 3 seconds will be used.
+type ClientOptions struct {
+	Cert          string
+	Key           string
+	CACert        string
+	DialTimeout   time.Duration
+	DialKeepAlive time.Duration
+	Username      string
+	Password      string
+
+	// Logger receives lost leases and failed watches, which the client
+	// recovers from on its own. By default, nothing is logged.
+	Logger log.Logger
+}
+
+// NewClient returns Client with a connection to the named machines. It will
+// return an error if a connection to the cluster cannot be made. Machines may
+// be given as host:port or as full URLs, e.g. "http://localhost:2379".
+func NewClient(ctx context.Context, machines []string, options ClientOptions) (Client, error) {
+	if options.DialTimeout == 0 {
+		options.DialTimeout = 3 * time.Second
+	}
+	if options.DialKeepAlive == 0 {
+		options.DialKeepAlive = 3 * time.Second
+	}
+	if options.Logger == nil {
+		options.Logger = log.NewNopLogger()
+	}
+
+	var tlsConfig *tls.Config
+	if options.Cert != "" && options.Key != "" {
+		tlsCert, err := tls.LoadX509KeyPair(options.Cert, options.Key)
+		if err != nil {
+			return nil, err
+		}
+		caCertCt, err := ioutil.ReadFile(options.CACert)
+		if err != nil {
+			return nil, err
+		}
+		caCertPool := x509.NewCertPool()
+		caCertPool.AppendCertsFromPEM(caCertCt)
+		tlsConfig = &tls.Config{
+			Certificates: []tls.Certificate{tlsCert},
+			RootCAs:      caCertPool,
+		}
+	}
+
+	cli, err := clientv3.New(clientv3.Config{
+		Context:           ctx,
+		Endpoints:         machines,
+		DialTimeout:       options.DialTimeout,
+		DialKeepAliveTime: options.DialKeepAlive,
+		TLS:               tlsConfig,
+		Username:          options.Username,
+		Password:          options.Password,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &client{
+		kv:      cli,
+		lease:   cli,
+		watcher: cli,
+		ctx:     ctx,
+		logger:  options.Logger,
+		retry:   retryInterval,
+		leases:  map[string]*lease{},
+	}, nil
+}
+
+// GetEntries implements the etcd Client interface.
+func (c *client) GetEntries(prefix string) ([]string, error) {
+	resp, err := c.kv.Get(c.ctx, prefix, clientv3.WithPrefix())
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]string, len(resp.Kvs))
+	for i, kv := range resp.Kvs {
+		entries[i] = string(kv.Value)
+	}
+	return entries, nil
+}
+
+// WatchPrefix implements the etcd Client interface.
+func (c *client) WatchPrefix(prefix string, ch chan struct{}) {
+	for {
+		watch := c.watcher.Watch(c.ctx, prefix, clientv3.WithPrefix())
+		ch <- struct{}{} // make sure caller invokes GetEntries
+
+		var err error
+		for resp := range watch {
+			if err = resp.Err(); err != nil {
+				break
+			}
+			ch <- struct{}{}
+		}
+
+		if c.ctx.Err() != nil {
+			return
+		}
+		c.logger.Log("prefix", prefix, "err", err, "action", "rewatch")
+		select {
+		case <-time.After(c.retry):
+		case <-c.ctx.Done():
+			return
+		}
+	}
+}
+
+func (c *client) Register(s Service) error {
+	if s.Key == "" {
+		return ErrNoKey
+	}
+	if s.Value == "" {
+		return ErrNoValue
+	}
+	if s.TTL == nil {
+		_, err := c.kv.Put(c.ctx, s.Key, s.Value)
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(c.ctx)
+	id, keepalive, err := c.grantAndPut(ctx, s)
+	if err != nil {
+		cancel()
+		return err
+	}
+	l := &lease{id: id, cancel: cancel, done: make(chan struct{})}
+
+	c.mtx.Lock()
+	prev, ok := c.leases[s.Key]
+	c.leases[s.Key] = l
+	c.mtx.Unlock()
+	if ok {
+		prev.cancel()
+	}
+
+	go c.keepAlive(ctx, s, l, keepalive)
+	return nil
+}
+
+// grantAndPut grants a lease for the service, puts its key with the lease, and
+// starts keeping the lease alive.
+func (c *client) grantAndPut(ctx context.Context, s Service) (clientv3.LeaseID, <-chan *clientv3.LeaseKeepAliveResponse, error) {
+	grant, err := c.lease.Grant(ctx, int64(s.TTL.ttl.Seconds()))
+	if err != nil {
+		return 0, nil, err
+	}
+	if _, err = c.kv.Put(ctx, s.Key, s.Value, clientv3.WithLease(grant.ID)); err == nil {
+		var keepalive <-chan *clientv3.LeaseKeepAliveResponse
+		if keepalive, err = c.lease.KeepAlive(ctx, grant.ID); err == nil {
+			return grant.ID, keepalive, nil
+		}
+	}
+	c.lease.Revoke(c.ctx, grant.ID)
+	return 0, nil, err
+}
+
+// keepAlive consumes the keepalive responses of the service's lease until ctx
+// is canceled. If the responses stop before, the lease has expired or been
+// revoked, so a new lease is granted and the key is put again.
+func (c *client) keepAlive(ctx context.Context, s Service, l *lease, keepalive <-chan *clientv3.LeaseKeepAliveResponse) {
+	defer close(l.done)
+	for {
+		for range keepalive {
+			// Responses must be consumed, or the client logs warnings.
+		}
+		if ctx.Err() != nil {
+			return // deregistered
+		}
+		c.logger.Log("key", s.Key, "msg", "lease lost", "action", "reregister")
+
+		for {
+			id, ka, err := c.grantAndPut(ctx, s)
+			if err == nil {
+				c.mtx.Lock()
+				l.id = id
+				c.mtx.Unlock()
+				keepalive = ka
+				break
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			c.logger.Log("key", s.Key, "err", err)
+			select {
+			case <-time.After(c.retry):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+func (c *client) Deregister(s Service) error {
+	if s.Key == "" {
+		return ErrNoKey
+	}
+
+	c.mtx.Lock()
+	l, ok := c.leases[s.Key]
+	delete(c.leases, s.Key)
+	c.mtx.Unlock()
+
+	// Stop the keepalive first, so it can't put the key again.
+	if ok {
+		l.cancel()
+		<-l.done
+	}
+	if _, err := c.kv.Delete(c.ctx, s.Key); err != nil {
+		return err
+	}
+	if !ok {
+		return nil
+	}
+
+	c.mtx.Lock()
+	id := l.id
+	c.mtx.Unlock()
+	_, err := c.lease.Revoke(c.ctx, id)
+	return err
+}
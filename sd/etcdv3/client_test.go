@@ -0,0 +1,225 @@
+package etcdv3
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/coreos/etcd/clientv3"
+
+	"github.com/guherbozdogan/kit/log"
+)
+
+// NewClient should fail when providing invalid or missing endpoints.
+func TestNewClientNoEndpoints(t *testing.T) {
+	c, err := NewClient(context.Background(), []string{}, ClientOptions{})
+	if err == nil {
+		t.Errorf("expected error: %v", err)
+	}
+	if c != nil {
+		t.Errorf("expected client to be nil on failure")
+	}
+}
+
+// NewClient should fail when the TLS material can't be loaded.
+func TestNewClientBadTLS(t *testing.T) {
+	_, err := NewClient(context.Background(), []string{"http://irrelevant:12345"}, ClientOptions{
+		Cert:   "does-not-exist.crt",
+		Key:    "does-not-exist.key",
+		CACert: "does-not-exist.ca",
+	})
+	if err == nil {
+		t.Errorf("expected error: %v", err)
+	}
+}
+
+func TestRegisterValidation(t *testing.T) {
+	c := &client{ctx: context.Background(), leases: map[string]*lease{}}
+
+	if want, have := ErrNoKey, c.Register(Service{Value: "v"}); want != have {
+		t.Errorf("want %v, have %v", want, have)
+	}
+	if want, have := ErrNoValue, c.Register(Service{Key: "k"}); want != have {
+		t.Errorf("want %v, have %v", want, have)
+	}
+	if want, have := ErrNoKey, c.Deregister(Service{}); want != have {
+		t.Errorf("want %v, have %v", want, have)
+	}
+}
+
+func TestRegisterLeaseLost(t *testing.T) {
+	var (
+		kv     = &fakeKV{puts: make(chan string, 2), keys: map[string]string{}}
+		lessor = &fakeLease{keepalives: map[clientv3.LeaseID]chan *clientv3.LeaseKeepAliveResponse{}}
+		c      = &client{kv: kv, lease: lessor, ctx: context.Background(), logger: log.NewNopLogger(), retry: time.Millisecond, leases: map[string]*lease{}}
+		s      = Service{Key: "/svc/1", Value: "1.2.3.4:8080", TTL: NewTTLOption(10 * time.Second)}
+	)
+
+	if err := c.Register(s); err != nil {
+		t.Fatal(err)
+	}
+	<-kv.puts
+
+	// The lease expires, e.g. after a partition, and the key with it.
+	lessor.expire(1)
+	kv.Delete(context.Background(), s.Key)
+	if want, have := s.Key, <-kv.puts; want != have {
+		t.Fatalf("want %q, have %q", want, have)
+	}
+	if want, have := 2, lessor.numGrants(); want != have {
+		t.Errorf("want %d, have %d", want, have)
+	}
+
+	if err := c.Deregister(s); err != nil {
+		t.Fatal(err)
+	}
+	if want, have := 0, kv.numKeys(); want != have {
+		t.Errorf("want %d, have %d", want, have)
+	}
+	if want, have := []clientv3.LeaseID{2}, lessor.revokedIDs(); !reflect.DeepEqual(want, have) {
+		t.Errorf("want %v, have %v", want, have)
+	}
+}
+
+func TestWatchPrefixRewatches(t *testing.T) {
+	var (
+		ctx, cancel = context.WithCancel(context.Background())
+		watcher     = &fakeWatcher{watches: make(chan chan clientv3.WatchResponse, 2)}
+		c           = &client{watcher: watcher, ctx: ctx, logger: log.NewNopLogger(), retry: time.Millisecond}
+		ch          = make(chan struct{})
+		done        = make(chan struct{})
+	)
+	go func() {
+		c.WatchPrefix("/svc", ch)
+		close(done)
+	}()
+
+	first := <-watcher.watches
+	<-ch // sentinel
+	first <- clientv3.WatchResponse{}
+	<-ch                                                // change
+	first <- clientv3.WatchResponse{CompactRevision: 1} // fails the watch
+
+	<-watcher.watches // watched again
+	<-ch              // sentinel, so the subscriber catches up
+
+	cancel()
+	<-done
+}
+
+type fakeKV struct {
+	clientv3.KV
+	puts chan string
+
+	mtx  sync.Mutex
+	keys map[string]string
+}
+
+func (kv *fakeKV) Put(ctx context.Context, key, val string, opts ...clientv3.OpOption) (*clientv3.PutResponse, error) {
+	kv.mtx.Lock()
+	kv.keys[key] = val
+	kv.mtx.Unlock()
+	kv.puts <- key
+	return &clientv3.PutResponse{}, nil
+}
+
+func (kv *fakeKV) Delete(ctx context.Context, key string, opts ...clientv3.OpOption) (*clientv3.DeleteResponse, error) {
+	kv.mtx.Lock()
+	defer kv.mtx.Unlock()
+	delete(kv.keys, key)
+	return &clientv3.DeleteResponse{}, nil
+}
+
+func (kv *fakeKV) numKeys() int {
+	kv.mtx.Lock()
+	defer kv.mtx.Unlock()
+	return len(kv.keys)
+}
+
+type fakeLease struct {
+	clientv3.Lease
+
+	mtx        sync.Mutex
+	grants     int
+	keepalives map[clientv3.LeaseID]chan *clientv3.LeaseKeepAliveResponse
+	revoked    []clientv3.LeaseID
+}
+
+func (l *fakeLease) Grant(ctx context.Context, ttl int64) (*clientv3.LeaseGrantResponse, error) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	l.grants++
+	return &clientv3.LeaseGrantResponse{ID: clientv3.LeaseID(l.grants), TTL: ttl}, nil
+}
+
+func (l *fakeLease) KeepAlive(ctx context.Context, id clientv3.LeaseID) (<-chan *clientv3.LeaseKeepAliveResponse, error) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	ch := make(chan *clientv3.LeaseKeepAliveResponse)
+	l.keepalives[id] = ch
+	go func() {
+		// Like the real client, close the channel when ctx is canceled.
+		<-ctx.Done()
+		l.expire(id)
+	}()
+	return ch, nil
+}
+
+func (l *fakeLease) Revoke(ctx context.Context, id clientv3.LeaseID) (*clientv3.LeaseRevokeResponse, error) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	l.revoked = append(l.revoked, id)
+	return &clientv3.LeaseRevokeResponse{}, nil
+}
+
+// expire closes the keepalive channel of the lease.
+func (l *fakeLease) expire(id clientv3.LeaseID) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	if ch, ok := l.keepalives[id]; ok {
+		close(ch)
+		delete(l.keepalives, id)
+	}
+}
+
+func (l *fakeLease) numGrants() int {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	return l.grants
+}
+
+func (l *fakeLease) revokedIDs() []clientv3.LeaseID {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+	return append([]clientv3.LeaseID{}, l.revoked...)
+}
+
+type fakeWatcher struct {
+	clientv3.Watcher
+	watches chan chan clientv3.WatchResponse
+}
+
+func (w *fakeWatcher) Watch(ctx context.Context, key string, opts ...clientv3.OpOption) clientv3.WatchChan {
+	ch := make(chan clientv3.WatchResponse)
+	w.watches <- ch
+	out := make(chan clientv3.WatchResponse)
+	go func() {
+		// Like the real client, close the channel on errors and when ctx is
+		// canceled.
+		defer close(out)
+		for {
+			select {
+			case resp := <-ch:
+				out <- resp
+				if resp.Err() != nil {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
@@ -0,0 +1,5 @@
+// Package etcdv3 provides a Subscriber and Registrar implementation for etcd,
+// using the etcd v3 API. Registrations with a TTL are bound to a lease which
+// the client keeps alive, and subscribers watch a key prefix for changes. If
+// your cluster still serves the v2 API, see package etcd instead.
+package etcdv3
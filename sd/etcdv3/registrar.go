@@ -0,0 +1,76 @@
+package etcdv3
+
+import (
+	"time"
+
+	"github.com/guherbozdogan/kit/log"
+)
+
+const minTTL = 3 * time.Second
+
+// Registrar registers service instance liveness information to etcd.
+type Registrar struct {
+	client  Client
+	service Service
+	logger  log.Logger
+}
+
+// Service holds the instance identifying data you want to publish to etcd. Key
+// must be unique, and value is the string returned to subscribers, typically
+// called the "instance" string in other parts of package sd.
+type Service struct {
+	Key   string // unique key, e.g. "/service/foobar/1.2.3.4:8080"
+	Value string // returned to subscribers, e.g. "http://1.2.3.4:8080"
+	TTL   *TTLOption
+}
+
+// TTLOption allows setting a key with a TTL. The key is attached to a lease,
+// which the client keeps alive for as long as the service is registered. If
+// the process dies, the lease expires and the key is removed.
+type TTLOption struct {
+	ttl time.Duration // e.g. time.Second * 10
+}
+
+// NewTTLOption returns a TTLOption with the given TTL, which is rounded down to
+// whole seconds. Values below 3s are raised to 3s, as etcd enforces a minimum
+// lease TTL of roughly that size.
+//
+// A good default value might be 10s.
+func NewTTLOption(ttl time.Duration) *TTLOption {
+	if ttl < minTTL {
+		ttl = minTTL
+	}
+	return &TTLOption{
+		ttl: ttl,
+	}
+}
+
+// NewRegistrar returns a etcd Registrar acting on the provided catalog
+// registration (service).
+func NewRegistrar(client Client, service Service, logger log.Logger) *Registrar {
+	return &Registrar{
+		client:  client,
+		service: service,
+		logger:  log.With(logger, "key", service.Key, "value", service.Value),
+	}
+}
+
+// Register implements the sd.Registrar interface. Call it when you want your
+// service to be registered in etcd, typically at startup.
+func (r *Registrar) Register() {
+	if err := r.client.Register(r.service); err != nil {
+		r.logger.Log("err", err)
+	} else {
+		r.logger.Log("action", "register")
+	}
+}
+
+// Deregister implements the sd.Registrar interface. Call it when you want your
+// service to be deregistered from etcd, typically just prior to shutdown.
+func (r *Registrar) Deregister() {
+	if err := r.client.Deregister(r.service); err != nil {
+		r.logger.Log("err", err)
+	} else {
+		r.logger.Log("action", "deregister")
+	}
+}
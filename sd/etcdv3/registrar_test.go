@@ -0,0 +1,75 @@
+package etcdv3
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/guherbozdogan/kit/log"
+)
+
+// testClient is a basic implementation of Client
+type testClient struct {
+	registerRes error // value returned when Register or Deregister is called
+}
+
+func (tc *testClient) GetEntries(prefix string) ([]string, error) {
+	return nil, nil
+}
+
+func (tc *testClient) WatchPrefix(prefix string, ch chan struct{}) {}
+
+func (tc *testClient) Register(s Service) error {
+	return tc.registerRes
+}
+
+func (tc *testClient) Deregister(s Service) error {
+	return tc.registerRes
+}
+
+// default service used to build registrar in our tests
+var testService = Service{Key: "testKey", Value: "testValue"}
+
+func TestRegister(t *testing.T) {
+	for _, tc := range []struct {
+		registerRes error  // value returned by the client on calls to Register
+		log         string // expected log by the registrar
+	}{
+		{errors.New("regError"), "key=testKey value=testValue err=regError\n"},
+		{nil, "key=testKey value=testValue action=register\n"},
+	} {
+		buf := &bytes.Buffer{}
+		r := NewRegistrar(&testClient{tc.registerRes}, testService, log.NewLogfmtLogger(buf))
+		r.Register()
+		if want, have := tc.log, buf.String(); want != have {
+			t.Errorf("want %q, have %q", want, have)
+		}
+	}
+}
+
+func TestDeregister(t *testing.T) {
+	for _, tc := range []struct {
+		deregisterRes error  // value returned by the client on calls to Deregister
+		log           string // expected log by the registrar
+	}{
+		{errors.New("deregError"), "key=testKey value=testValue err=deregError\n"},
+		{nil, "key=testKey value=testValue action=deregister\n"},
+	} {
+		buf := &bytes.Buffer{}
+		r := NewRegistrar(&testClient{tc.deregisterRes}, testService, log.NewLogfmtLogger(buf))
+		r.Deregister()
+		if want, have := tc.log, buf.String(); want != have {
+			t.Errorf("want %q, have %q", want, have)
+		}
+	}
+}
+
+func TestNewTTLOption(t *testing.T) {
+	if want, have := minTTL, NewTTLOption(time.Second).ttl; want != have {
+		t.Errorf("want %v, have %v", want, have)
+	}
+	if want, have := 10*time.Second, NewTTLOption(10*time.Second).ttl; want != have {
+		t.Errorf("want %v, have %v", want, have)
+	}
+}
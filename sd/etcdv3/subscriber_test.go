@@ -0,0 +1,120 @@
+package etcdv3
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/guherbozdogan/kit/endpoint"
+	"github.com/guherbozdogan/kit/log"
+)
+
+func TestSubscriber(t *testing.T) {
+	factory := func(string) (endpoint.Endpoint, io.Closer, error) {
+		return endpoint.Nop, nil, nil
+	}
+
+	client := newFakeClient(map[string][]string{"/foo": {"1:1", "1:2"}})
+
+	s, err := NewSubscriber(client, "/foo", factory, log.NewNopLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Stop()
+
+	endpoints, err := s.Endpoints()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, have := 2, len(endpoints); want != have {
+		t.Errorf("want %d, have %d", want, have)
+	}
+
+	client.set("/foo", []string{"1:1", "1:2", "1:3"})
+	time.Sleep(100 * time.Millisecond)
+
+	endpoints, err = s.Endpoints()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, have := 3, len(endpoints); want != have {
+		t.Errorf("want %d, have %d", want, have)
+	}
+}
+
+func TestBadFactory(t *testing.T) {
+	factory := func(string) (endpoint.Endpoint, io.Closer, error) {
+		return nil, nil, errors.New("kaboom")
+	}
+
+	client := newFakeClient(map[string][]string{"/foo": {"1:1", "1:2"}})
+
+	s, err := NewSubscriber(client, "/foo", factory, log.NewNopLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Stop()
+
+	endpoints, err := s.Endpoints()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if want, have := 0, len(endpoints); want != have {
+		t.Errorf("want %d, have %d", want, have)
+	}
+}
+
+// fakeClient serves entries from memory, and signals watchers whenever they
+// are changed via set.
+type fakeClient struct {
+	mtx      sync.Mutex
+	entries  map[string][]string
+	watchers map[string][]chan struct{}
+}
+
+func newFakeClient(entries map[string][]string) *fakeClient {
+	return &fakeClient{
+		entries:  entries,
+		watchers: map[string][]chan struct{}{},
+	}
+}
+
+func (c *fakeClient) set(prefix string, entries []string) {
+	c.mtx.Lock()
+	c.entries[prefix] = entries
+	watchers := c.watchers[prefix]
+	c.mtx.Unlock()
+
+	for _, ch := range watchers {
+		ch <- struct{}{}
+	}
+}
+
+func (c *fakeClient) GetEntries(prefix string) ([]string, error) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	entries, ok := c.entries[prefix]
+	if !ok {
+		return nil, errors.New("key not exist")
+	}
+	return entries, nil
+}
+
+func (c *fakeClient) WatchPrefix(prefix string, ch chan struct{}) {
+	c.mtx.Lock()
+	c.watchers[prefix] = append(c.watchers[prefix], ch)
+	c.mtx.Unlock()
+	ch <- struct{}{}
+}
+
+func (c *fakeClient) Register(Service) error {
+	return nil
+}
+
+func (c *fakeClient) Deregister(Service) error {
+	return nil
+}
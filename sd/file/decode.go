@@ -0,0 +1,70 @@
+package file
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Decoder converts the contents of an instance file to a set of instance
+// strings (e.g. host:port).
+type Decoder func(data []byte) ([]string, error)
+
+// DecoderFor returns the decoder appropriate to the extension of the given
+// path: DecodeJSON for .json, DecodeYAML for .yaml and .yml, and DecodeText
+// for anything else.
+func DecoderFor(path string) Decoder {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return DecodeJSON
+	case ".yaml", ".yml":
+		return DecodeYAML
+	default:
+		return DecodeText
+	}
+}
+
+// DecodeJSON decodes a JSON array of instance strings.
+func DecodeJSON(data []byte) ([]string, error) {
+	var instances []string
+	if len(bytes.TrimSpace(data)) == 0 {
+		return instances, nil
+	}
+	if err := json.Unmarshal(data, &instances); err != nil {
+		return nil, err
+	}
+	return instances, nil
+}
+
+// DecodeYAML decodes a YAML sequence of instance strings.
+func DecodeYAML(data []byte) ([]string, error) {
+	var instances []string
+	if err := yaml.Unmarshal(data, &instances); err != nil {
+		return nil, err
+	}
+	return instances, nil
+}
+
+// DecodeText decodes one instance string per line. Blank lines, surrounding
+// whitespace and anything following a # are ignored.
+func DecodeText(data []byte) ([]string, error) {
+	var (
+		instances []string
+		s         = bufio.NewScanner(bytes.NewReader(data))
+	)
+	for s.Scan() {
+		line := s.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		if line = strings.TrimSpace(line); line == "" {
+			continue
+		}
+		instances = append(instances, line)
+	}
+	return instances, s.Err()
+}
@@ -0,0 +1,34 @@
+package file
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDecoders(t *testing.T) {
+	want := []string{"1.0.0.1:1001", "1.0.0.2:1002"}
+	for _, tc := range []struct {
+		path string
+		data string
+	}{
+		{"instances.json", `["1.0.0.1:1001", "1.0.0.2:1002"]`},
+		{"instances.yaml", "- 1.0.0.1:1001\n- 1.0.0.2:1002\n"},
+		{"instances.yml", "- 1.0.0.1:1001\n- 1.0.0.2:1002\n"},
+		{"instances", "# comment\n1.0.0.1:1001\n\n  1.0.0.2:1002 # trailing\n"},
+	} {
+		have, err := DecoderFor(tc.path)([]byte(tc.data))
+		if err != nil {
+			t.Errorf("%s: %v", tc.path, err)
+			continue
+		}
+		if !reflect.DeepEqual(want, have) {
+			t.Errorf("%s: want %v, have %v", tc.path, want, have)
+		}
+	}
+}
+
+func TestDecodeJSONInvalid(t *testing.T) {
+	if _, err := DecodeJSON([]byte(`{"not": "a list"}`)); err == nil {
+		t.Error("want error, have none")
+	}
+}
@@ -0,0 +1,5 @@
+// Package file provides a subscriber implementation that reads instances from
+// a file on disk. The file is re-read on a fixed schedule, so instances may be
+// added or removed by editing it, e.g. during local development or when a
+// sidecar process manages the list on behalf of the service.
+package file
@@ -0,0 +1,118 @@
+package file
+
+import (
+	"bytes"
+	"io/ioutil"
+	"time"
+
+	"github.com/guherbozdogan/kit/endpoint"
+	"github.com/guherbozdogan/kit/log"
+	"github.com/guherbozdogan/kit/sd"
+	"github.com/guherbozdogan/kit/sd/cache"
+)
+
+// Subscriber yields endpoints for the instances listed in a file. The file is
+// read on a fixed schedule, and the endpoints are updated whenever its contents
+// change. If the file can't be read or decoded, the last good set of endpoints
+// is kept.
+type Subscriber struct {
+	path   string
+	decode Decoder
+	cache  *cache.Cache
+	logger log.Logger
+	last   []byte
+	quit   chan struct{}
+}
+
+var _ sd.Subscriber = &Subscriber{}
+
+// NewSubscriber returns a file subscriber which re-reads the file at path every
+// ttl. The file format is chosen by DecoderFor.
+func NewSubscriber(
+	path string,
+	ttl time.Duration,
+	factory sd.Factory,
+	logger log.Logger,
+) *Subscriber {
+	return NewSubscriberDetailed(path, time.NewTicker(ttl), DecoderFor(path), factory, logger)
+}
+
+// NewSubscriberDetailed is the same as NewSubscriber, but allows users to
+// provide an explicit refresh ticker instead of a TTL, and specify the decoder
+// instead of deducing it from the file extension.
+func NewSubscriberDetailed(
+	path string,
+	refresh *time.Ticker,
+	decode Decoder,
+	factory sd.Factory,
+	logger log.Logger,
+) *Subscriber {
+	s := &Subscriber{
+		path:   path,
+		decode: decode,
+		cache:  cache.New(factory, logger),
+		logger: log.With(logger, "path", path),
+		quit:   make(chan struct{}),
+	}
+
+	instances, _, err := s.read()
+	if err == nil {
+		s.logger.Log("instances", len(instances))
+	} else {
+		s.logger.Log("err", err)
+	}
+	s.cache.Update(instances)
+
+	go s.loop(refresh)
+	return s
+}
+
+// Stop terminates the Subscriber.
+func (s *Subscriber) Stop() {
+	close(s.quit)
+}
+
+// Endpoints implements the Subscriber interface.
+func (s *Subscriber) Endpoints() ([]endpoint.Endpoint, error) {
+	return s.cache.Endpoints(), nil
+}
+
+func (s *Subscriber) loop(t *time.Ticker) {
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			instances, changed, err := s.read()
+			if err != nil {
+				s.logger.Log("err", err)
+				continue // don't replace potentially-good with bad
+			}
+			if !changed {
+				continue
+			}
+			s.logger.Log("instances", len(instances))
+			s.cache.Update(instances)
+
+		case <-s.quit:
+			return
+		}
+	}
+}
+
+// read loads and decodes the file. It reports whether the contents changed
+// since the last successful read.
+func (s *Subscriber) read() ([]string, bool, error) {
+	data, err := ioutil.ReadFile(s.path)
+	if err != nil {
+		return []string{}, false, err
+	}
+	if s.last != nil && bytes.Equal(data, s.last) {
+		return nil, false, nil
+	}
+	instances, err := s.decode(data)
+	if err != nil {
+		return []string{}, false, err
+	}
+	s.last = data
+	return instances, true, nil
+}
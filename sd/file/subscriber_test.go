@@ -0,0 +1,108 @@
+package file
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/guherbozdogan/kit/endpoint"
+	"github.com/guherbozdogan/kit/log"
+)
+
+func TestRefresh(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sd-file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "instances.txt")
+
+	write := func(contents string) {
+		if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("")
+
+	ticker := time.NewTicker(time.Second)
+	ticker.Stop()
+	tickc := make(chan time.Time)
+	ticker.C = tickc
+
+	var generates, closes uint64
+	factory := func(instance string) (endpoint.Endpoint, io.Closer, error) {
+		t.Logf("factory(%q)", instance)
+		atomic.AddUint64(&generates, 1)
+		return endpoint.Nop, closer(func() { atomic.AddUint64(&closes, 1) }), nil
+	}
+
+	subscriber := NewSubscriberDetailed(path, ticker, DecodeText, factory, log.NewNopLogger())
+	defer subscriber.Stop()
+
+	// First read, empty
+	endpoints, err := subscriber.Endpoints()
+	if err != nil {
+		t.Error(err)
+	}
+	if want, have := 0, len(endpoints); want != have {
+		t.Errorf("want %d, have %d", want, have)
+	}
+
+	// Add some instances and read again
+	write("1.0.0.1:1001\n1.0.0.2:1002\n1.0.0.3:1003\n")
+	tickc <- time.Now()
+	time.Sleep(100 * time.Millisecond)
+
+	endpoints, err = subscriber.Endpoints()
+	if err != nil {
+		t.Error(err)
+	}
+	if want, have := 3, len(endpoints); want != have {
+		t.Errorf("want %d, have %d", want, have)
+	}
+	if want, have := uint64(3), atomic.LoadUint64(&generates); want != have {
+		t.Errorf("want %d, have %d", want, have)
+	}
+
+	// Remove one instance
+	write("1.0.0.1:1001\n1.0.0.3:1003\n")
+	tickc <- time.Now()
+	time.Sleep(100 * time.Millisecond)
+
+	endpoints, err = subscriber.Endpoints()
+	if err != nil {
+		t.Error(err)
+	}
+	if want, have := 2, len(endpoints); want != have {
+		t.Errorf("want %d, have %d", want, have)
+	}
+	if want, have := uint64(3), atomic.LoadUint64(&generates); want != have {
+		t.Errorf("want %d, have %d", want, have)
+	}
+	if want, have := uint64(1), atomic.LoadUint64(&closes); want != have {
+		t.Errorf("want %d, have %d", want, have)
+	}
+
+	// A missing file keeps the last good set
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	tickc <- time.Now()
+	time.Sleep(100 * time.Millisecond)
+
+	endpoints, err = subscriber.Endpoints()
+	if err != nil {
+		t.Error(err)
+	}
+	if want, have := 2, len(endpoints); want != have {
+		t.Errorf("want %d, have %d", want, have)
+	}
+}
+
+type closer func()
+
+func (c closer) Close() error { c(); return nil }
@@ -0,0 +1,62 @@
+package zk
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"strconv"
+)
+
+// ErrNoCuratorPort indicates a Curator service instance carries neither a port
+// nor an SSL port.
+var ErrNoCuratorPort = errors.New("curator service instance has no port")
+
+// CuratorServiceInstance is the JSON node payload written by the Apache Curator
+// service discovery extension, when it's used with its default
+// JsonInstanceSerializer.
+type CuratorServiceInstance struct {
+	Name                string          `json:"name"`
+	ID                  string          `json:"id"`
+	Address             string          `json:"address"`
+	Port                *int            `json:"port"`
+	SSLPort             *int            `json:"sslPort"`
+	Payload             json.RawMessage `json:"payload"`
+	RegistrationTimeUTC int64           `json:"registrationTimeUTC"`
+	ServiceType         string          `json:"serviceType"`
+	URISpec             *CuratorURISpec `json:"uriSpec"`
+}
+
+// CuratorURISpec is the URI template of a Curator service instance.
+type CuratorURISpec struct {
+	Parts []struct {
+		Value    string `json:"value"`
+		Variable bool   `json:"variable"`
+	} `json:"parts"`
+}
+
+// ParseCuratorServiceInstance decodes a node payload written by Curator.
+// Factories may use it to get at the metadata of an instance when the
+// subscriber yields raw payloads, which is the default.
+func ParseCuratorServiceInstance(payload string) (CuratorServiceInstance, error) {
+	var instance CuratorServiceInstance
+	err := json.Unmarshal([]byte(payload), &instance)
+	return instance, err
+}
+
+// CuratorInstance is an InstanceFunc which decodes a node payload written by
+// Curator, and yields its address as host:port. The SSL port is used only if
+// the instance has no plain port.
+func CuratorInstance(payload string) (string, error) {
+	instance, err := ParseCuratorServiceInstance(payload)
+	if err != nil {
+		return "", err
+	}
+	port := instance.Port
+	if port == nil {
+		port = instance.SSLPort
+	}
+	if port == nil {
+		return "", ErrNoCuratorPort
+	}
+	return net.JoinHostPort(instance.Address, strconv.Itoa(*port)), nil
+}
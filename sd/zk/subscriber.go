@@ -12,18 +12,34 @@ import (
 // Subscriber yield endpoints stored in a certain ZooKeeper path. Any kind of
 // change in that path is watched and will update the Subscriber endpoints.
 type Subscriber struct {
-	client Client
-	path   string
-	cache  *cache.Cache
-	logger log.Logger
-	quitc  chan struct{}
+	client   Client
+	path     string
+	cache    *cache.Cache
+	logger   log.Logger
+	instance InstanceFunc
+	quitc    chan struct{}
 }
 
 var _ sd.Subscriber = &Subscriber{}
 
+// InstanceFunc converts the payload of a child node to the instance string
+// that's passed to the subscriber's factory.
+type InstanceFunc func(payload string) (string, error)
+
+// SubscriberOption sets an optional parameter for subscribers.
+type SubscriberOption func(*Subscriber)
+
+// SubscriberInstance sets the function that converts node payloads to instance
+// strings. Nodes whose payload can't be converted are skipped. By default, the
+// payload is used as the instance string as-is. Use CuratorInstance to consume
+// services registered through Apache Curator.
+func SubscriberInstance(f InstanceFunc) SubscriberOption {
+	return func(s *Subscriber) { s.instance = f }
+}
+
 // NewSubscriber returns a ZooKeeper subscriber. ZooKeeper will start watching
 // the given path for changes and update the Subscriber endpoints.
-func NewSubscriber(c Client, path string, factory sd.Factory, logger log.Logger) (*Subscriber, error) {
+func NewSubscriber(c Client, path string, factory sd.Factory, logger log.Logger, options ...SubscriberOption) (*Subscriber, error) {
 	s := &Subscriber{
 		client: c,
 		path:   path,
@@ -31,6 +47,9 @@ func NewSubscriber(c Client, path string, factory sd.Factory, logger log.Logger)
 		logger: logger,
 		quitc:  make(chan struct{}),
 	}
+	for _, option := range options {
+		option(s)
+	}
 
 	err := s.client.CreateParentNodes(s.path)
 	if err != nil {
@@ -42,6 +61,7 @@ func NewSubscriber(c Client, path string, factory sd.Factory, logger log.Logger)
 		logger.Log("path", s.path, "msg", "failed to retrieve entries", "err", err)
 		return nil, err
 	}
+	instances = s.makeInstances(instances)
 	logger.Log("path", s.path, "instances", len(instances))
 	s.cache.Update(instances)
 
@@ -66,6 +86,7 @@ func (s *Subscriber) loop(eventc <-chan zk.Event) {
 				s.logger.Log("path", s.path, "msg", "failed to retrieve entries", "err", err)
 				continue
 			}
+			instances = s.makeInstances(instances)
 			s.logger.Log("path", s.path, "instances", len(instances))
 			s.cache.Update(instances)
 
@@ -84,3 +105,19 @@ func (s *Subscriber) Endpoints() ([]endpoint.Endpoint, error) {
 func (s *Subscriber) Stop() {
 	close(s.quitc)
 }
+
+func (s *Subscriber) makeInstances(payloads []string) []string {
+	if s.instance == nil {
+		return payloads
+	}
+	instances := make([]string, 0, len(payloads))
+	for _, payload := range payloads {
+		instance, err := s.instance(payload)
+		if err != nil {
+			s.logger.Log("path", s.path, "payload", payload, "err", err)
+			continue
+		}
+		instances = append(instances, instance)
+	}
+	return instances
+}
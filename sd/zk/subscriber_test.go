@@ -1,8 +1,14 @@
 package zk
 
 import (
+	"io"
+	"reflect"
+	"sort"
+	"sync"
 	"testing"
 	"time"
+
+	"github.com/guherbozdogan/kit/endpoint"
 )
 
 func TestSubscriber(t *testing.T) {
@@ -115,3 +121,65 @@ func TestBadSubscriberCreate(t *testing.T) {
 		t.Error("expected Subscriber not to be created")
 	}
 }
+
+func TestCuratorSubscriber(t *testing.T) {
+	client := newFakeClient()
+
+	var (
+		mtx       sync.Mutex
+		instances []string
+	)
+	factory := func(instance string) (endpoint.Endpoint, io.Closer, error) {
+		mtx.Lock()
+		defer mtx.Unlock()
+		instances = append(instances, instance)
+		return endpoint.Nop, nil, nil
+	}
+
+	s, err := NewSubscriber(client, path, factory, logger, SubscriberInstance(CuratorInstance))
+	if err != nil {
+		t.Fatalf("failed to create new Subscriber: %v", err)
+	}
+	defer s.Stop()
+
+	// instance1 registered through Curator
+	client.AddService(path+"/instance1", `{"name":"service.name","id":"b7bd9b2c","address":"10.0.0.1","port":8080,"sslPort":null,"payload":null,"registrationTimeUTC":1499943470101,"serviceType":"DYNAMIC","uriSpec":null}`)
+
+	if err = asyncTest(100*time.Millisecond, 1, s); err != nil {
+		t.Error(err)
+	}
+
+	// instance2 has an unexpected payload, and is skipped
+	client.AddService(path+"/instance2", "10.0.0.2:8080")
+
+	// instance3 only serves TLS
+	client.AddService(path+"/instance3", `{"name":"service.name","id":"4a1b0e6d","address":"10.0.0.3","port":null,"sslPort":8443,"serviceType":"DYNAMIC"}`)
+
+	if err = asyncTest(100*time.Millisecond, 2, s); err != nil {
+		t.Error(err)
+	}
+
+	mtx.Lock()
+	defer mtx.Unlock()
+	sort.Strings(instances)
+	if want, have := []string{"10.0.0.1:8080", "10.0.0.3:8443"}, instances; !reflect.DeepEqual(want, have) {
+		t.Errorf("want %v, have %v", want, have)
+	}
+}
+
+func TestParseCuratorServiceInstance(t *testing.T) {
+	instance, err := ParseCuratorServiceInstance(`{"name":"search","id":"1","address":"10.0.0.1","port":80,"payload":{"version":"1.2"}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, have := "search", instance.Name; want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+	if want, have := `{"version":"1.2"}`, string(instance.Payload); want != have {
+		t.Errorf("want %s, have %s", want, have)
+	}
+
+	if _, err := CuratorInstance(`{"address":"10.0.0.1"}`); err != ErrNoCuratorPort {
+		t.Errorf("want %v, have %v", ErrNoCuratorPort, err)
+	}
+}
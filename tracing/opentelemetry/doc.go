@@ -0,0 +1,8 @@
+// Package opentelemetry provides Go kit integration to the OpenTelemetry
+// project. Spans are propagated with the OpenTelemetry propagator passed to the
+// request funcs, e.g. propagation.TraceContext{} for W3C Trace Context.
+//
+// The API mirrors package tracing/opentracing, to ease migration: replace
+// TraceServer, TraceClient and the transport request funcs one by one. Package
+// metrics/opentelemetry provides the corresponding metrics bridge.
+package opentelemetry
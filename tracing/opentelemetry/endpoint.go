@@ -0,0 +1,56 @@
+package opentelemetry
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/guherbozdogan/kit/endpoint"
+)
+
+// TraceServer returns a Middleware that wraps the `next` Endpoint in an
+// OpenTelemetry Span called `operationName`.
+//
+// If `ctx` already has a recording Span, e.g. one started by FromHTTPRequest
+// or FromGRPCRequest, it is re-used, renamed and ended here. Otherwise, a new
+// server Span is started. Errors returned by `next` are recorded on the Span.
+func TraceServer(tracer trace.Tracer, operationName string) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			serverSpan := trace.SpanFromContext(ctx)
+			if serverSpan.IsRecording() {
+				serverSpan.SetName(operationName)
+			} else {
+				ctx, serverSpan = tracer.Start(ctx, operationName, trace.WithSpanKind(trace.SpanKindServer))
+			}
+			defer serverSpan.End()
+			response, err := next(ctx, request)
+			recordError(serverSpan, err)
+			return response, err
+		}
+	}
+}
+
+// TraceClient returns a Middleware that wraps the `next` Endpoint in an
+// OpenTelemetry client Span called `operationName`, which is a child of the
+// Span in `ctx`, if any. Errors returned by `next` are recorded on the Span.
+func TraceClient(tracer trace.Tracer, operationName string) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			ctx, clientSpan := tracer.Start(ctx, operationName, trace.WithSpanKind(trace.SpanKindClient))
+			defer clientSpan.End()
+			response, err := next(ctx, request)
+			recordError(clientSpan, err)
+			return response, err
+		}
+	}
+}
+
+func recordError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
@@ -0,0 +1,86 @@
+package opentelemetry_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/guherbozdogan/kit/endpoint"
+	kitotel "github.com/guherbozdogan/kit/tracing/opentelemetry"
+)
+
+func newTracer() (trace.Tracer, *tracetest.SpanRecorder) {
+	rec := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+	return provider.Tracer("test"), rec
+}
+
+func TestTraceServer(t *testing.T) {
+	tracer, rec := newTracer()
+
+	// Initialize the ctx with a Span to re-use.
+	ctx, span := tracer.Start(context.Background(), "before")
+
+	var innerSpan trace.Span
+	e := kitotel.TraceServer(tracer, "testOp")(func(ctx context.Context, _ interface{}) (interface{}, error) {
+		innerSpan = trace.SpanFromContext(ctx)
+		return nil, errors.New("boom")
+	})
+	e(ctx, struct{}{})
+
+	if innerSpan != span {
+		t.Error("Should re-use the span in the context")
+	}
+	spans := rec.Ended()
+	if want, have := 1, len(spans); want != have {
+		t.Fatalf("want %d, have %d", want, have)
+	}
+	if want, have := "testOp", spans[0].Name(); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+	if want, have := codes.Error, spans[0].Status().Code; want != have {
+		t.Errorf("want %s, have %s", want, have)
+	}
+}
+
+func TestTraceServerNoContextSpan(t *testing.T) {
+	tracer, rec := newTracer()
+
+	kitotel.TraceServer(tracer, "testOp")(endpoint.Nop)(context.Background(), struct{}{})
+
+	spans := rec.Ended()
+	if want, have := 1, len(spans); want != have {
+		t.Fatalf("want %d, have %d", want, have)
+	}
+	if want, have := trace.SpanKindServer, spans[0].SpanKind(); want != have {
+		t.Errorf("want %s, have %s", want, have)
+	}
+	if spans[0].Parent().IsValid() {
+		t.Error("want a root span")
+	}
+}
+
+func TestTraceClient(t *testing.T) {
+	tracer, rec := newTracer()
+
+	ctx, parent := tracer.Start(context.Background(), "parent")
+	kitotel.TraceClient(tracer, "testOp")(endpoint.Nop)(ctx, struct{}{})
+	parent.End()
+
+	spans := rec.Ended()
+	if want, have := 2, len(spans); want != have {
+		t.Fatalf("want %d, have %d", want, have)
+	}
+	client := spans[0]
+	if want, have := trace.SpanKindClient, client.SpanKind(); want != have {
+		t.Errorf("want %s, have %s", want, have)
+	}
+	if want, have := parent.SpanContext().SpanID(), client.Parent().SpanID(); want != have {
+		t.Errorf("want %s, have %s", want, have)
+	}
+}
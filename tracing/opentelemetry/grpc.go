@@ -0,0 +1,60 @@
+package opentelemetry
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/metadata"
+)
+
+// ToGRPCRequest returns a grpc RequestFunc that injects the Span context found
+// in `ctx` into the grpc Metadata with the propagator, e.g.
+// propagation.TraceContext{}.
+func ToGRPCRequest(propagator propagation.TextMapPropagator) func(ctx context.Context, md *metadata.MD) context.Context {
+	return func(ctx context.Context, md *metadata.MD) context.Context {
+		propagator.Inject(ctx, metadataCarrier{md})
+		return ctx
+	}
+}
+
+// FromGRPCRequest returns a grpc RequestFunc that tries to join with a trace
+// found in the grpc Metadata with the propagator, and starts a new server Span
+// called `operationName` accordingly. If no trace could be found, the Span will
+// be a trace root. The Span is incorporated in the returned Context and can be
+// retrieved with trace.SpanFromContext(ctx). It must be ended, e.g. by
+// TraceServer.
+func FromGRPCRequest(tracer trace.Tracer, propagator propagation.TextMapPropagator, operationName string) func(ctx context.Context, md metadata.MD) context.Context {
+	return func(ctx context.Context, md metadata.MD) context.Context {
+		ctx = propagator.Extract(ctx, metadataCarrier{&md})
+		ctx, _ = tracer.Start(ctx, operationName, trace.WithSpanKind(trace.SpanKindServer))
+		return ctx
+	}
+}
+
+// metadataCarrier conforms to propagation.TextMapCarrier.
+type metadataCarrier struct {
+	*metadata.MD
+}
+
+func (c metadataCarrier) Get(key string) string {
+	values := (*c.MD)[strings.ToLower(key)]
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	// capital "Key" is illegal in HTTP/2.
+	(*c.MD)[strings.ToLower(key)] = []string{value}
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(*c.MD))
+	for key := range *c.MD {
+		keys = append(keys, key)
+	}
+	return keys
+}
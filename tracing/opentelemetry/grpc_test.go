@@ -0,0 +1,38 @@
+package opentelemetry_test
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/metadata"
+
+	kitotel "github.com/guherbozdogan/kit/tracing/opentelemetry"
+)
+
+func TestTraceGRPCRequestRoundtrip(t *testing.T) {
+	var (
+		tracer, rec = newTracer()
+		propagator  = propagation.TraceContext{}
+	)
+
+	beforeCtx, beforeSpan := tracer.Start(context.Background(), "to_inject")
+	md := metadata.MD{}
+	kitotel.ToGRPCRequest(propagator)(beforeCtx, &md)
+	if len(md["traceparent"]) == 0 {
+		t.Fatal("want traceparent metadata, have none")
+	}
+
+	joinCtx := kitotel.FromGRPCRequest(tracer, propagator, "joined")(context.Background(), md)
+	trace.SpanFromContext(joinCtx).End()
+	beforeSpan.End()
+
+	spans := rec.Ended()
+	if want, have := 2, len(spans); want != have {
+		t.Fatalf("want %d, have %d", want, have)
+	}
+	if want, have := beforeSpan.SpanContext().SpanID(), spans[0].Parent().SpanID(); want != have {
+		t.Errorf("want %s, have %s", want, have)
+	}
+}
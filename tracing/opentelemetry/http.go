@@ -0,0 +1,64 @@
+package opentelemetry
+
+import (
+	"context"
+	"net/http"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	kithttp "github.com/guherbozdogan/kit/transport/http"
+)
+
+// ToHTTPRequest returns an http RequestFunc that injects the Span context found
+// in `ctx` into the http headers with the propagator, e.g.
+// propagation.TraceContext{} for the W3C traceparent and tracestate headers.
+// If `ctx` has a recording Span, the request is described on it.
+func ToHTTPRequest(propagator propagation.TextMapPropagator) kithttp.RequestFunc {
+	return func(ctx context.Context, req *http.Request) context.Context {
+		if span := trace.SpanFromContext(ctx); span.IsRecording() {
+			span.SetAttributes(
+				attribute.String("http.method", req.Method),
+				attribute.String("http.url", req.URL.String()),
+			)
+		}
+		propagator.Inject(ctx, propagation.HeaderCarrier(req.Header))
+		return ctx
+	}
+}
+
+// FromHTTPRequest returns an http RequestFunc that tries to join with a trace
+// found in `req` with the propagator, and starts a new server Span called
+// `operationName` accordingly. If no trace could be found in `req`, the Span
+// will be a trace root. The Span is incorporated in the returned Context and
+// can be retrieved with trace.SpanFromContext(ctx). It must be ended, e.g. by
+// TraceServer.
+func FromHTTPRequest(tracer trace.Tracer, propagator propagation.TextMapPropagator, operationName string) kithttp.RequestFunc {
+	return func(ctx context.Context, req *http.Request) context.Context {
+		ctx = propagator.Extract(ctx, propagation.HeaderCarrier(req.Header))
+		ctx, _ = tracer.Start(ctx, operationName,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				attribute.String("http.method", req.Method),
+				attribute.String("http.target", req.URL.Path),
+			),
+		)
+		return ctx
+	}
+}
+
+// HTTPClientResponse returns an http ClientResponseFunc that records the
+// status code of the response on the Span found in `ctx`, and marks the Span
+// as failed for 4xx and 5xx responses.
+func HTTPClientResponse() kithttp.ClientResponseFunc {
+	return func(ctx context.Context, resp *http.Response) context.Context {
+		span := trace.SpanFromContext(ctx)
+		span.SetAttributes(attribute.Int("http.status_code", resp.StatusCode))
+		if resp.StatusCode >= 400 {
+			span.SetStatus(codes.Error, http.StatusText(resp.StatusCode))
+		}
+		return ctx
+	}
+}
@@ -0,0 +1,48 @@
+package opentelemetry_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	kitotel "github.com/guherbozdogan/kit/tracing/opentelemetry"
+)
+
+func TestTraceHTTPRequestRoundtrip(t *testing.T) {
+	var (
+		tracer, rec = newTracer()
+		propagator  = propagation.TraceContext{}
+	)
+
+	beforeCtx, beforeSpan := tracer.Start(context.Background(), "to_inject")
+	req, _ := http.NewRequest("GET", "http://test.biz/path", nil)
+	afterCtx := kitotel.ToHTTPRequest(propagator)(beforeCtx, req)
+	if trace.SpanFromContext(afterCtx) != beforeSpan {
+		t.Error("Should not swap in a new span")
+	}
+	if req.Header.Get("traceparent") == "" {
+		t.Fatal("want traceparent header, have none")
+	}
+
+	joinCtx := kitotel.FromHTTPRequest(tracer, propagator, "joined")(context.Background(), req)
+	trace.SpanFromContext(joinCtx).End()
+	beforeSpan.End()
+
+	spans := rec.Ended()
+	if want, have := 2, len(spans); want != have {
+		t.Fatalf("want %d, have %d", want, have)
+	}
+	joined := spans[0]
+	if want, have := beforeSpan.SpanContext().TraceID(), joined.SpanContext().TraceID(); want != have {
+		t.Errorf("want %s, have %s", want, have)
+	}
+	if want, have := beforeSpan.SpanContext().SpanID(), joined.Parent().SpanID(); want != have {
+		t.Errorf("want %s, have %s", want, have)
+	}
+	if !joined.Parent().IsRemote() {
+		t.Error("want remote parent")
+	}
+}
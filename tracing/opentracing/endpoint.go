@@ -5,16 +5,53 @@ import (
 
 	"github.com/opentracing/opentracing-go"
 	otext "github.com/opentracing/opentracing-go/ext"
+	otlog "github.com/opentracing/opentracing-go/log"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 
 	"github.com/guherbozdogan/kit/endpoint"
+	kithttp "github.com/guherbozdogan/kit/transport/http"
 )
 
+// EndpointOption sets an optional parameter for the TraceServer and
+// TraceClient middlewares.
+type EndpointOption func(*endpointOptions)
+
+type endpointOptions struct {
+	tags     opentracing.Tags
+	tagsFunc func(ctx context.Context) opentracing.Tags
+}
+
+// WithTags adds the tags to every Span.
+func WithTags(tags opentracing.Tags) EndpointOption {
+	return func(o *endpointOptions) {
+		if o.tags == nil {
+			o.tags = opentracing.Tags{}
+		}
+		for k, v := range tags {
+			o.tags[k] = v
+		}
+	}
+}
+
+// WithTagsFunc adds the tags returned by the function to every Span. It's
+// called with the request context, e.g. to tag values set by ServerBefore
+// funcs.
+func WithTagsFunc(f func(ctx context.Context) opentracing.Tags) EndpointOption {
+	return func(o *endpointOptions) { o.tagsFunc = f }
+}
+
 // TraceServer returns a Middleware that wraps the `next` Endpoint in an
 // OpenTracing Span called `operationName`.
 //
 // If `ctx` already has a Span, it is re-used and the operation name is
 // overwritten. If `ctx` does not yet have a Span, one is created here.
-func TraceServer(tracer opentracing.Tracer, operationName string) endpoint.Middleware {
+//
+// If `next` fails, the error tag is set and the error is logged on the Span.
+// Status codes of responses and errors implementing transport/http.StatusCoder,
+// and codes of gRPC errors are tagged as well.
+func TraceServer(tracer opentracing.Tracer, operationName string, options ...EndpointOption) endpoint.Middleware {
+	o := makeEndpointOptions(options)
 	return func(next endpoint.Endpoint) endpoint.Endpoint {
 		return func(ctx context.Context, request interface{}) (interface{}, error) {
 			serverSpan := opentracing.SpanFromContext(ctx)
@@ -26,15 +63,20 @@ func TraceServer(tracer opentracing.Tracer, operationName string) endpoint.Middl
 			}
 			defer serverSpan.Finish()
 			otext.SpanKindRPCServer.Set(serverSpan)
+			o.setTags(ctx, serverSpan)
 			ctx = opentracing.ContextWithSpan(ctx, serverSpan)
-			return next(ctx, request)
+			response, err := next(ctx, request)
+			setResult(serverSpan, response, err)
+			return response, err
 		}
 	}
 }
 
 // TraceClient returns a Middleware that wraps the `next` Endpoint in an
-// OpenTracing Span called `operationName`.
-func TraceClient(tracer opentracing.Tracer, operationName string) endpoint.Middleware {
+// OpenTracing Span called `operationName`. Errors are recorded as in
+// TraceServer.
+func TraceClient(tracer opentracing.Tracer, operationName string, options ...EndpointOption) endpoint.Middleware {
+	o := makeEndpointOptions(options)
 	return func(next endpoint.Endpoint) endpoint.Endpoint {
 		return func(ctx context.Context, request interface{}) (interface{}, error) {
 			var clientSpan opentracing.Span
@@ -48,8 +90,52 @@ func TraceClient(tracer opentracing.Tracer, operationName string) endpoint.Middl
 			}
 			defer clientSpan.Finish()
 			otext.SpanKindRPCClient.Set(clientSpan)
+			o.setTags(ctx, clientSpan)
 			ctx = opentracing.ContextWithSpan(ctx, clientSpan)
-			return next(ctx, request)
+			response, err := next(ctx, request)
+			setResult(clientSpan, response, err)
+			return response, err
+		}
+	}
+}
+
+func makeEndpointOptions(options []EndpointOption) endpointOptions {
+	var o endpointOptions
+	for _, option := range options {
+		option(&o)
+	}
+	return o
+}
+
+func (o endpointOptions) setTags(ctx context.Context, span opentracing.Span) {
+	for k, v := range o.tags {
+		span.SetTag(k, v)
+	}
+	if o.tagsFunc != nil {
+		for k, v := range o.tagsFunc(ctx) {
+			span.SetTag(k, v)
+		}
+	}
+}
+
+// setResult records the outcome of an endpoint on the span.
+func setResult(span opentracing.Span, response interface{}, err error) {
+	if err == nil {
+		if sc, ok := response.(kithttp.StatusCoder); ok {
+			otext.HTTPStatusCode.Set(span, uint16(sc.StatusCode()))
 		}
+		return
+	}
+
+	otext.Error.Set(span, true)
+	span.LogFields(otlog.String("event", "error"), otlog.Error(err))
+	if sc, ok := err.(kithttp.StatusCoder); ok {
+		otext.HTTPStatusCode.Set(span, uint16(sc.StatusCode()))
+	}
+	if code := grpc.Code(err); code != codes.Unknown {
+		span.SetTag(grpcStatusCodeTag, code.String())
 	}
 }
+
+// grpcStatusCodeTag is the tag gRPC status codes are recorded under.
+const grpcStatusCodeTag = "grpc.status_code"
@@ -2,10 +2,13 @@ package opentracing_test
 
 import (
 	"context"
+	"net/http"
 	"testing"
 
 	"github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/mocktracer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 
 	"github.com/guherbozdogan/kit/endpoint"
 	kitot "github.com/guherbozdogan/kit/tracing/opentracing"
@@ -115,3 +118,75 @@ func TestTraceClientNoContextSpan(t *testing.T) {
 		t.Fatalf("Want %q, have %q", want, have)
 	}
 }
+
+type statusError struct{ code int }
+
+func (e statusError) Error() string   { return "status error" }
+func (e statusError) StatusCode() int { return e.code }
+
+func TestTraceServerError(t *testing.T) {
+	tracer := mocktracer.New()
+
+	tracedEndpoint := kitot.TraceServer(tracer, "testOp")(func(context.Context, interface{}) (interface{}, error) {
+		return nil, statusError{http.StatusTeapot}
+	})
+	if _, err := tracedEndpoint(context.Background(), struct{}{}); err == nil {
+		t.Fatal("want error, have nil")
+	}
+
+	endpointSpan := tracer.FinishedSpans()[0]
+	if want, have := true, endpointSpan.Tag("error"); want != have {
+		t.Errorf("Want %v, have %v", want, have)
+	}
+	if want, have := uint16(http.StatusTeapot), endpointSpan.Tag("http.status_code"); want != have {
+		t.Errorf("Want %v, have %v", want, have)
+	}
+	logs := endpointSpan.Logs()
+	if want, have := 1, len(logs); want != have {
+		t.Fatalf("Want %d log(s), have %d", want, have)
+	}
+	if want, have := "error", logs[0].Fields[0].ValueString; want != have {
+		t.Errorf("Want %q, have %q", want, have)
+	}
+}
+
+func TestTraceClientGRPCError(t *testing.T) {
+	tracer := mocktracer.New()
+
+	tracedEndpoint := kitot.TraceClient(tracer, "testOp")(func(context.Context, interface{}) (interface{}, error) {
+		return nil, grpc.Errorf(codes.NotFound, "not found")
+	})
+	tracedEndpoint(context.Background(), struct{}{})
+
+	endpointSpan := tracer.FinishedSpans()[0]
+	if want, have := true, endpointSpan.Tag("error"); want != have {
+		t.Errorf("Want %v, have %v", want, have)
+	}
+	if want, have := "NotFound", endpointSpan.Tag("grpc.status_code"); want != have {
+		t.Errorf("Want %v, have %v", want, have)
+	}
+}
+
+func TestTraceEndpointTags(t *testing.T) {
+	tracer := mocktracer.New()
+
+	type key struct{}
+	tracedEndpoint := kitot.TraceServer(tracer, "testOp",
+		kitot.WithTags(opentracing.Tags{"static": "yes"}),
+		kitot.WithTagsFunc(func(ctx context.Context) opentracing.Tags {
+			return opentracing.Tags{"dynamic": ctx.Value(key{})}
+		}),
+	)(endpoint.Nop)
+	tracedEndpoint(context.WithValue(context.Background(), key{}, "value"), struct{}{})
+
+	endpointSpan := tracer.FinishedSpans()[0]
+	if want, have := "yes", endpointSpan.Tag("static"); want != have {
+		t.Errorf("Want %v, have %v", want, have)
+	}
+	if want, have := "value", endpointSpan.Tag("dynamic"); want != have {
+		t.Errorf("Want %v, have %v", want, have)
+	}
+	if have := endpointSpan.Tag("error"); have != nil {
+		t.Errorf("Want no error tag, have %v", have)
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/opentracing/opentracing-go"
 	"github.com/opentracing/opentracing-go/ext"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 
 	"github.com/guherbozdogan/kit/log"
@@ -44,6 +45,18 @@ func FromGRPCRequest(tracer opentracing.Tracer, operationName string, logger log
 	}
 }
 
+// GRPCClientResponse returns a grpc ClientResponseFunc that tags the OK status
+// code on the OpenTracing Span found in `ctx`. Client response funcs only run
+// for successful calls; codes of failed calls are tagged by TraceClient.
+func GRPCClientResponse() func(ctx context.Context, header metadata.MD, trailer metadata.MD) context.Context {
+	return func(ctx context.Context, _ metadata.MD, _ metadata.MD) context.Context {
+		if span := opentracing.SpanFromContext(ctx); span != nil {
+			span.SetTag(grpcStatusCodeTag, codes.OK.String())
+		}
+		return ctx
+	}
+}
+
 // A type that conforms to opentracing.TextMapReader and
 // opentracing.TextMapWriter.
 type metadataReaderWriter struct {
@@ -69,3 +69,18 @@ func FromHTTPRequest(tracer opentracing.Tracer, operationName string, logger log
 		return opentracing.ContextWithSpan(ctx, span)
 	}
 }
+
+// HTTPClientResponse returns an http ClientResponseFunc that tags the status
+// code of the response on the OpenTracing Span found in `ctx`, and sets the
+// error tag for 5xx responses.
+func HTTPClientResponse() kithttp.ClientResponseFunc {
+	return func(ctx context.Context, resp *http.Response) context.Context {
+		if span := opentracing.SpanFromContext(ctx); span != nil {
+			ext.HTTPStatusCode.Set(span, uint16(resp.StatusCode))
+			if resp.StatusCode >= http.StatusInternalServerError {
+				ext.Error.Set(span, true)
+			}
+		}
+		return ctx
+	}
+}
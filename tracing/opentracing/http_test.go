@@ -107,3 +107,19 @@ func TestFromHTTPRequestTags(t *testing.T) {
 		t.Errorf("Want %q, have %q", want, have)
 	}
 }
+
+func TestHTTPClientResponse(t *testing.T) {
+	tracer := mocktracer.New()
+	span := tracer.StartSpan("client").(*mocktracer.MockSpan)
+	ctx := opentracing.ContextWithSpan(context.Background(), span)
+
+	kitot.HTTPClientResponse()(ctx, &http.Response{StatusCode: http.StatusBadGateway})
+	span.Finish()
+
+	if want, have := uint16(http.StatusBadGateway), span.Tag("http.status_code"); want != have {
+		t.Errorf("Want %v, have %v", want, have)
+	}
+	if want, have := true, span.Tag("error"); want != have {
+		t.Errorf("Want %v, have %v", want, have)
+	}
+}
@@ -0,0 +1,6 @@
+// Package zipkin provides Go kit integration to Zipkin tracing, using the
+// native zipkin-go tracer rather than OpenTracing. Spans are propagated with B3
+// headers, in the multi-header (X-B3-TraceId etc.) or the single-header (b3)
+// format, and reported through any zipkin-go reporter: the HTTP reporter for
+// production, or the in-memory recorder for tests.
+package zipkin
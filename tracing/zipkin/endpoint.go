@@ -0,0 +1,60 @@
+package zipkin
+
+import (
+	"context"
+
+	"github.com/openzipkin/zipkin-go"
+	"github.com/openzipkin/zipkin-go/model"
+
+	"github.com/guherbozdogan/kit/endpoint"
+)
+
+// TraceServer returns a Middleware that wraps the `next` Endpoint in a Zipkin
+// Span called `operationName`.
+//
+// If `ctx` already has a Span, e.g. one created by FromHTTPRequest or
+// FromGRPCRequest, it is re-used and its name is overwritten. If `ctx` does
+// not yet have a Span, one is created here. Errors returned by `next` are
+// tagged on the Span.
+func TraceServer(tracer *zipkin.Tracer, operationName string) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			serverSpan := zipkin.SpanFromContext(ctx)
+			if serverSpan == nil {
+				// All we can do is create a new root span.
+				serverSpan = tracer.StartSpan(operationName, zipkin.Kind(model.Server))
+			} else {
+				serverSpan.SetName(operationName)
+			}
+			defer serverSpan.Finish()
+			ctx = zipkin.NewContext(ctx, serverSpan)
+			response, err := next(ctx, request)
+			if err != nil {
+				zipkin.TagError.Set(serverSpan, err.Error())
+			}
+			return response, err
+		}
+	}
+}
+
+// TraceClient returns a Middleware that wraps the `next` Endpoint in a Zipkin
+// Span called `operationName`, which is a child of the Span in `ctx`, if any.
+// Errors returned by `next` are tagged on the Span.
+func TraceClient(tracer *zipkin.Tracer, operationName string) endpoint.Middleware {
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			options := []zipkin.SpanOption{zipkin.Kind(model.Client)}
+			if parentSpan := zipkin.SpanFromContext(ctx); parentSpan != nil {
+				options = append(options, zipkin.Parent(parentSpan.Context()))
+			}
+			clientSpan := tracer.StartSpan(operationName, options...)
+			defer clientSpan.Finish()
+			ctx = zipkin.NewContext(ctx, clientSpan)
+			response, err := next(ctx, request)
+			if err != nil {
+				zipkin.TagError.Set(clientSpan, err.Error())
+			}
+			return response, err
+		}
+	}
+}
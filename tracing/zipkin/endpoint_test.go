@@ -0,0 +1,93 @@
+package zipkin_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/openzipkin/zipkin-go"
+	"github.com/openzipkin/zipkin-go/model"
+	"github.com/openzipkin/zipkin-go/reporter/recorder"
+
+	"github.com/guherbozdogan/kit/endpoint"
+	kitzipkin "github.com/guherbozdogan/kit/tracing/zipkin"
+)
+
+func newTracer(t *testing.T) (*zipkin.Tracer, *recorder.ReporterRecorder) {
+	rec := recorder.NewReporter()
+	tracer, err := zipkin.NewTracer(rec, zipkin.WithSharedSpans(false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tracer, rec
+}
+
+func TestTraceServer(t *testing.T) {
+	tracer, rec := newTracer(t)
+
+	// Initialize the ctx with a Span to re-use.
+	span := tracer.StartSpan("before", zipkin.Kind(model.Server))
+	ctx := zipkin.NewContext(context.Background(), span)
+
+	var innerSpan zipkin.Span
+	e := kitzipkin.TraceServer(tracer, "testOp")(func(ctx context.Context, _ interface{}) (interface{}, error) {
+		innerSpan = zipkin.SpanFromContext(ctx)
+		return nil, errors.New("boom")
+	})
+	e(ctx, struct{}{})
+
+	if innerSpan != span {
+		t.Error("Should re-use the span in the context")
+	}
+	spans := rec.Flush()
+	if want, have := 1, len(spans); want != have {
+		t.Fatalf("want %d, have %d", want, have)
+	}
+	if want, have := "testOp", spans[0].Name; want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+	if want, have := "boom", spans[0].Tags["error"]; want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+}
+
+func TestTraceServerNoContextSpan(t *testing.T) {
+	tracer, rec := newTracer(t)
+
+	kitzipkin.TraceServer(tracer, "testOp")(endpoint.Nop)(context.Background(), struct{}{})
+
+	spans := rec.Flush()
+	if want, have := 1, len(spans); want != have {
+		t.Fatalf("want %d, have %d", want, have)
+	}
+	if want, have := model.Server, spans[0].Kind; want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+	if spans[0].ParentID != nil {
+		t.Error("want a root span")
+	}
+}
+
+func TestTraceClient(t *testing.T) {
+	tracer, rec := newTracer(t)
+
+	parent := tracer.StartSpan("parent")
+	ctx := zipkin.NewContext(context.Background(), parent)
+	kitzipkin.TraceClient(tracer, "testOp")(endpoint.Nop)(ctx, struct{}{})
+	parent.Finish()
+
+	spans := rec.Flush()
+	if want, have := 2, len(spans); want != have {
+		t.Fatalf("want %d, have %d", want, have)
+	}
+	client := spans[0]
+	if want, have := model.Client, client.Kind; want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+	if client.ParentID == nil || *client.ParentID != parent.Context().ID {
+		t.Errorf("want parent %s, have %v", parent.Context().ID, client.ParentID)
+	}
+	if want, have := parent.Context().TraceID, client.TraceID; want != have {
+		t.Errorf("want %s, have %s", want, have)
+	}
+}
@@ -0,0 +1,82 @@
+package zipkin
+
+import (
+	"context"
+	"time"
+
+	"github.com/openzipkin/zipkin-go"
+	"github.com/openzipkin/zipkin-go/model"
+	"github.com/openzipkin/zipkin-go/propagation/b3"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/guherbozdogan/kit/log"
+)
+
+// b3SingleKey is the single b3 header, lowercased as gRPC metadata requires.
+const b3SingleKey = "b3"
+
+// ToGRPCRequest returns a grpc RequestFunc that injects a Zipkin Span found in
+// `ctx` into the grpc Metadata, in the B3 format selected by the options. If
+// no such Span can be found, the RequestFunc is a noop.
+func ToGRPCRequest(tracer *zipkin.Tracer, logger log.Logger, opts ...Option) func(ctx context.Context, md *metadata.MD) context.Context {
+	o := makeOptions(opts)
+	return func(ctx context.Context, md *metadata.MD) context.Context {
+		if span := zipkin.SpanFromContext(ctx); span != nil {
+			sc := span.Context()
+			if o.multi {
+				// There's nothing we can do with an error here.
+				if err := b3.InjectGRPC(md)(sc); err != nil {
+					logger.Log("err", err)
+				}
+			}
+			if o.single {
+				(*md)[b3SingleKey] = []string{b3.BuildSingleHeader(sc)}
+			}
+		}
+		return ctx
+	}
+}
+
+// FromGRPCRequest returns a grpc RequestFunc that tries to join with a Zipkin
+// trace found in the grpc Metadata, in either B3 format, and starts a new
+// server Span called `operationName` accordingly. If no trace could be found,
+// the Span will be a trace root. The Span is incorporated in the returned
+// Context and can be retrieved with zipkin.SpanFromContext(ctx). It must be
+// finished, e.g. by TraceServer.
+func FromGRPCRequest(tracer *zipkin.Tracer, operationName string, logger log.Logger) func(ctx context.Context, md metadata.MD) context.Context {
+	return func(ctx context.Context, md metadata.MD) context.Context {
+		var sc model.SpanContext
+		if single := b3.GetGRPCHeader(&md, b3SingleKey); single != "" {
+			sc = tracer.Extract(func() (*model.SpanContext, error) { return b3.ParseSingleHeader(single) })
+		} else {
+			sc = tracer.Extract(b3.ExtractGRPC(&md))
+		}
+		if sc.Err != nil {
+			logger.Log("err", sc.Err)
+		}
+		span := tracer.StartSpan(operationName, zipkin.Kind(model.Server), zipkin.Parent(sc))
+		return zipkin.NewContext(ctx, span)
+	}
+}
+
+// GRPCClientResponse returns a grpc ClientResponseFunc that annotates the
+// Zipkin Span found in `ctx` with when the response was received.
+func GRPCClientResponse() func(ctx context.Context, header metadata.MD, trailer metadata.MD) context.Context {
+	return func(ctx context.Context, _ metadata.MD, _ metadata.MD) context.Context {
+		if span := zipkin.SpanFromContext(ctx); span != nil {
+			span.Annotate(time.Now(), "wr")
+		}
+		return ctx
+	}
+}
+
+// GRPCServerResponse returns a grpc ServerResponseFunc that annotates the
+// Zipkin Span found in `ctx` with when the response was sent.
+func GRPCServerResponse() func(ctx context.Context, header *metadata.MD, trailer *metadata.MD) context.Context {
+	return func(ctx context.Context, _ *metadata.MD, _ *metadata.MD) context.Context {
+		if span := zipkin.SpanFromContext(ctx); span != nil {
+			span.Annotate(time.Now(), "ws")
+		}
+		return ctx
+	}
+}
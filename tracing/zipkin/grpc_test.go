@@ -0,0 +1,55 @@
+package zipkin_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/openzipkin/zipkin-go"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/guherbozdogan/kit/log"
+	kitzipkin "github.com/guherbozdogan/kit/tracing/zipkin"
+)
+
+func TestTraceGRPCRequestRoundtrip(t *testing.T) {
+	logger := log.NewNopLogger()
+
+	for _, tc := range []struct {
+		name    string
+		options []kitzipkin.Option
+	}{
+		{"multi", nil},
+		{"single", []kitzipkin.Option{kitzipkin.B3SingleHeader()}},
+	} {
+		tracer, rec := newTracer(t)
+		beforeSpan := tracer.StartSpan("to_inject")
+		beforeCtx := zipkin.NewContext(context.Background(), beforeSpan)
+
+		md := metadata.MD{}
+		kitzipkin.ToGRPCRequest(tracer, logger, tc.options...)(beforeCtx, &md)
+		if len(md) == 0 {
+			t.Errorf("%s: want metadata, have none", tc.name)
+		}
+
+		joinCtx := kitzipkin.FromGRPCRequest(tracer, "joined", logger)(context.Background(), md)
+		joinedSpan := zipkin.SpanFromContext(joinCtx)
+		kitzipkin.GRPCServerResponse()(joinCtx, &metadata.MD{}, &metadata.MD{})
+		joinedSpan.Finish()
+		beforeSpan.Finish()
+
+		spans := rec.Flush()
+		if want, have := 2, len(spans); want != have {
+			t.Fatalf("%s: want %d, have %d", tc.name, want, have)
+		}
+		joined := spans[0]
+		if want, have := beforeSpan.Context().TraceID, joined.TraceID; want != have {
+			t.Errorf("%s: want %s, have %s", tc.name, want, have)
+		}
+		if joined.ParentID == nil || *joined.ParentID != beforeSpan.Context().ID {
+			t.Errorf("%s: want parent %s, have %v", tc.name, beforeSpan.Context().ID, joined.ParentID)
+		}
+		if want, have := "ws", joined.Annotations[0].Value; want != have {
+			t.Errorf("%s: want %q, have %q", tc.name, want, have)
+		}
+	}
+}
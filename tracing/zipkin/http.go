@@ -0,0 +1,93 @@
+package zipkin
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/openzipkin/zipkin-go"
+	"github.com/openzipkin/zipkin-go/model"
+	"github.com/openzipkin/zipkin-go/propagation/b3"
+
+	"github.com/guherbozdogan/kit/log"
+	kithttp "github.com/guherbozdogan/kit/transport/http"
+)
+
+// ToHTTPRequest returns an http RequestFunc that injects a Zipkin Span found
+// in `ctx` into the http headers, in the B3 format selected by the options. If
+// no such Span can be found, the RequestFunc is a noop.
+func ToHTTPRequest(tracer *zipkin.Tracer, logger log.Logger, opts ...Option) kithttp.RequestFunc {
+	o := makeOptions(opts)
+	var injectOptions []b3.InjectOption
+	switch {
+	case o.single && o.multi:
+		injectOptions = append(injectOptions, b3.WithSingleAndMultiHeader())
+	case o.single:
+		injectOptions = append(injectOptions, b3.WithSingleHeaderOnly())
+	}
+
+	return func(ctx context.Context, req *http.Request) context.Context {
+		// Try to find a Span in the Context.
+		if span := zipkin.SpanFromContext(ctx); span != nil {
+			zipkin.TagHTTPMethod.Set(span, req.Method)
+			zipkin.TagHTTPUrl.Set(span, req.URL.String())
+			if endpoint, err := zipkin.NewEndpoint("", req.URL.Host); err == nil {
+				span.SetRemoteEndpoint(endpoint)
+			}
+
+			// There's nothing we can do with any errors here.
+			if err := b3.InjectHTTP(req, injectOptions...)(span.Context()); err != nil {
+				logger.Log("err", err)
+			}
+		}
+		return ctx
+	}
+}
+
+// FromHTTPRequest returns an http RequestFunc that tries to join with a Zipkin
+// trace found in `req`, in either B3 format, and starts a new server Span
+// called `operationName` accordingly. If no trace could be found in `req`, the
+// Span will be a trace root. The Span is incorporated in the returned Context
+// and can be retrieved with zipkin.SpanFromContext(ctx). It must be finished,
+// e.g. by TraceServer.
+func FromHTTPRequest(tracer *zipkin.Tracer, operationName string, logger log.Logger) kithttp.RequestFunc {
+	return func(ctx context.Context, req *http.Request) context.Context {
+		sc := tracer.Extract(b3.ExtractHTTP(req))
+		if sc.Err != nil {
+			logger.Log("err", sc.Err)
+		}
+		span := tracer.StartSpan(operationName, zipkin.Kind(model.Server), zipkin.Parent(sc))
+		zipkin.TagHTTPMethod.Set(span, req.Method)
+		zipkin.TagHTTPPath.Set(span, req.URL.Path)
+		return zipkin.NewContext(ctx, span)
+	}
+}
+
+// HTTPClientResponse returns an http ClientResponseFunc that records the
+// response on the Zipkin Span found in `ctx`: it tags the status code, tags an
+// error for non-2xx responses, and annotates when the response was received.
+func HTTPClientResponse() kithttp.ClientResponseFunc {
+	return func(ctx context.Context, resp *http.Response) context.Context {
+		if span := zipkin.SpanFromContext(ctx); span != nil {
+			span.Annotate(time.Now(), "wr")
+			code := strconv.Itoa(resp.StatusCode)
+			zipkin.TagHTTPStatusCode.Set(span, code)
+			if resp.StatusCode < 200 || resp.StatusCode > 299 {
+				zipkin.TagError.Set(span, code)
+			}
+		}
+		return ctx
+	}
+}
+
+// HTTPServerResponse returns an http ServerResponseFunc that annotates the
+// Zipkin Span found in `ctx` with when the response was sent.
+func HTTPServerResponse() kithttp.ServerResponseFunc {
+	return func(ctx context.Context, _ http.ResponseWriter) context.Context {
+		if span := zipkin.SpanFromContext(ctx); span != nil {
+			span.Annotate(time.Now(), "ws")
+		}
+		return ctx
+	}
+}
@@ -0,0 +1,83 @@
+package zipkin_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/openzipkin/zipkin-go"
+
+	"github.com/guherbozdogan/kit/log"
+	kitzipkin "github.com/guherbozdogan/kit/tracing/zipkin"
+)
+
+func TestTraceHTTPRequestRoundtrip(t *testing.T) {
+	logger := log.NewNopLogger()
+
+	for _, tc := range []struct {
+		name    string
+		options []kitzipkin.Option
+		single  bool
+		multi   bool
+	}{
+		{"multi", nil, false, true},
+		{"single", []kitzipkin.Option{kitzipkin.B3SingleHeader()}, true, false},
+		{"both", []kitzipkin.Option{kitzipkin.B3SingleAndMultiHeader()}, true, true},
+	} {
+		tracer, rec := newTracer(t)
+		beforeSpan := tracer.StartSpan("to_inject")
+		beforeCtx := zipkin.NewContext(context.Background(), beforeSpan)
+
+		req, _ := http.NewRequest("GET", "http://test.biz/path", nil)
+		afterCtx := kitzipkin.ToHTTPRequest(tracer, logger, tc.options...)(beforeCtx, req)
+		if zipkin.SpanFromContext(afterCtx) != beforeSpan {
+			t.Errorf("%s: should not swap in a new span", tc.name)
+		}
+		if want, have := tc.single, req.Header.Get("b3") != ""; want != have {
+			t.Errorf("%s: single header: want %v, have %v", tc.name, want, have)
+		}
+		if want, have := tc.multi, req.Header.Get("X-B3-TraceId") != ""; want != have {
+			t.Errorf("%s: multi header: want %v, have %v", tc.name, want, have)
+		}
+
+		joinCtx := kitzipkin.FromHTTPRequest(tracer, "joined", logger)(context.Background(), req)
+		joinedSpan := zipkin.SpanFromContext(joinCtx)
+		joinedSpan.Finish()
+		beforeSpan.Finish()
+
+		spans := rec.Flush()
+		if want, have := 2, len(spans); want != have {
+			t.Fatalf("%s: want %d, have %d", tc.name, want, have)
+		}
+		joined := spans[0]
+		if want, have := beforeSpan.Context().TraceID, joined.TraceID; want != have {
+			t.Errorf("%s: want %s, have %s", tc.name, want, have)
+		}
+		if joined.ParentID == nil || *joined.ParentID != beforeSpan.Context().ID {
+			t.Errorf("%s: want parent %s, have %v", tc.name, beforeSpan.Context().ID, joined.ParentID)
+		}
+		if want, have := "/path", joined.Tags["http.path"]; want != have {
+			t.Errorf("%s: want %q, have %q", tc.name, want, have)
+		}
+	}
+}
+
+func TestHTTPClientResponse(t *testing.T) {
+	tracer, rec := newTracer(t)
+	span := tracer.StartSpan("client")
+	ctx := zipkin.NewContext(context.Background(), span)
+
+	kitzipkin.HTTPClientResponse()(ctx, &http.Response{StatusCode: http.StatusNotFound})
+	span.Finish()
+
+	spans := rec.Flush()
+	if want, have := "404", spans[0].Tags["http.status_code"]; want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+	if want, have := "404", spans[0].Tags["error"]; want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+	if want, have := 1, len(spans[0].Annotations); want != have {
+		t.Errorf("want %d, have %d", want, have)
+	}
+}
@@ -0,0 +1,30 @@
+package zipkin
+
+// Option configures how spans are propagated to outgoing requests.
+type Option func(*options)
+
+type options struct {
+	single bool
+	multi  bool
+}
+
+// B3SingleHeader propagates spans in the single b3 header only, which is more
+// compact, but not understood by older Zipkin instrumentation.
+func B3SingleHeader() Option {
+	return func(o *options) { o.single, o.multi = true, false }
+}
+
+// B3SingleAndMultiHeader propagates spans in both the single b3 header and the
+// X-B3-* headers, which is useful while migrating to the single header.
+func B3SingleAndMultiHeader() Option {
+	return func(o *options) { o.single, o.multi = true, true }
+}
+
+// makeOptions returns the options, which default to the X-B3-* headers only.
+func makeOptions(opts []Option) options {
+	o := options{multi: true}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
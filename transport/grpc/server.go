@@ -1,12 +1,16 @@
 package grpc
 
 import (
+	"context"
+
 	oldcontext "golang.org/x/net/context"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
 
 	"github.com/guherbozdogan/kit/endpoint"
 	"github.com/guherbozdogan/kit/log"
+	"github.com/guherbozdogan/kit/transport/internal/inflight"
 )
 
 // Handler which should be called from the gRPC binding of the service
@@ -18,12 +22,13 @@ type Handler interface {
 
 // Server wraps an endpoint and implements grpc.Handler.
 type Server struct {
-	e      endpoint.Endpoint
-	dec    DecodeRequestFunc
-	enc    EncodeResponseFunc
-	before []ServerRequestFunc
-	after  []ServerResponseFunc
-	logger log.Logger
+	e        endpoint.Endpoint
+	dec      DecodeRequestFunc
+	enc      EncodeResponseFunc
+	before   []ServerRequestFunc
+	after    []ServerResponseFunc
+	logger   log.Logger
+	inflight *inflight.Tracker
 }
 
 // NewServer constructs a new server, which implements wraps the provided
@@ -38,10 +43,11 @@ func NewServer(
 	options ...ServerOption,
 ) *Server {
 	s := &Server{
-		e:      e,
-		dec:    dec,
-		enc:    enc,
-		logger: log.NewNopLogger(),
+		e:        e,
+		dec:      dec,
+		enc:      enc,
+		logger:   log.NewNopLogger(),
+		inflight: inflight.New(),
 	}
 	for _, option := range options {
 		option(s)
@@ -72,6 +78,11 @@ func ServerErrorLogger(logger log.Logger) ServerOption {
 
 // ServeGRPC implements the Handler interface.
 func (s Server) ServeGRPC(ctx oldcontext.Context, req interface{}) (oldcontext.Context, interface{}, error) {
+	if !s.inflight.Begin() {
+		return ctx, nil, ErrDraining
+	}
+	defer s.inflight.End()
+
 	// Retrieve gRPC metadata.
 	md, ok := metadata.FromContext(ctx)
 	if !ok {
@@ -121,3 +132,25 @@ func (s Server) ServeGRPC(ctx oldcontext.Context, req interface{}) (oldcontext.C
 
 	return ctx, grpcResp, nil
 }
+
+// Drain makes the server reject new requests with ErrDraining, while requests
+// that are already in flight proceed normally. It's typically called after the
+// service is deregistered from service discovery, and before Wait.
+func (s Server) Drain() {
+	s.inflight.Drain()
+}
+
+// Wait blocks until the server has no requests in flight, or until the context
+// is done, in which case the context's error is returned.
+func (s Server) Wait(ctx context.Context) error {
+	return s.inflight.Wait(ctx)
+}
+
+// InFlight returns the number of requests currently being served.
+func (s Server) InFlight() int64 {
+	return s.inflight.InFlight()
+}
+
+// ErrDraining is returned for requests received after Drain was called. It
+// carries the UNAVAILABLE code, so clients may retry against another instance.
+var ErrDraining = grpc.Errorf(codes.Unavailable, "server is draining")
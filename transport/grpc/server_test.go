@@ -0,0 +1,55 @@
+package grpc_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	grpctransport "github.com/guherbozdogan/kit/transport/grpc"
+)
+
+func TestServerDraining(t *testing.T) {
+	var (
+		stepch  = make(chan bool)
+		handler = grpctransport.NewServer(
+			func(context.Context, interface{}) (interface{}, error) { <-stepch; return struct{}{}, nil },
+			func(context.Context, interface{}) (interface{}, error) { return struct{}{}, nil },
+			func(context.Context, interface{}) (interface{}, error) { return struct{}{}, nil },
+		)
+	)
+
+	// One request in flight.
+	errc := make(chan error)
+	go func() {
+		_, _, err := handler.ServeGRPC(context.Background(), struct{}{})
+		errc <- err
+	}()
+	for handler.InFlight() != 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// New requests are rejected while draining.
+	handler.Drain()
+	_, _, err := handler.ServeGRPC(context.Background(), struct{}{})
+	if want, have := codes.Unavailable, grpc.Code(err); want != have {
+		t.Errorf("want %s, have %s", want, have)
+	}
+
+	// Wait blocks until the in-flight request finishes.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if want, have := context.DeadlineExceeded, handler.Wait(ctx); want != have {
+		t.Errorf("want %v, have %v", want, have)
+	}
+
+	stepch <- true
+	if err := <-errc; err != nil {
+		t.Error(err)
+	}
+	if err := handler.Wait(context.Background()); err != nil {
+		t.Error(err)
+	}
+}
@@ -3,10 +3,12 @@ package http
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/guherbozdogan/kit/endpoint"
 	"github.com/guherbozdogan/kit/log"
+	"github.com/guherbozdogan/kit/transport/internal/inflight"
 )
 
 // Server wraps an endpoint and implements http.Handler.
@@ -19,6 +21,7 @@ type Server struct {
 	errorEncoder ErrorEncoder
 	finalizer    ServerFinalizerFunc
 	logger       log.Logger
+	inflight     *inflight.Tracker
 }
 
 // NewServer constructs a new server, which implements http.Server and wraps
@@ -35,6 +38,7 @@ func NewServer(
 		enc:          enc,
 		errorEncoder: DefaultErrorEncoder,
 		logger:       log.NewNopLogger(),
+		inflight:     inflight.New(),
 	}
 	for _, option := range options {
 		option(s)
@@ -94,6 +98,12 @@ func (s Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		w = iw
 	}
 
+	if !s.inflight.Begin() {
+		s.errorEncoder(ctx, ErrDraining, w)
+		return
+	}
+	defer s.inflight.End()
+
 	for _, f := range s.before {
 		ctx = f(ctx, r)
 	}
@@ -123,6 +133,35 @@ func (s Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// Drain makes the server reject new requests with ErrDraining, while requests
+// that are already in flight proceed normally. It's typically called after the
+// service is deregistered from service discovery, and before Wait.
+func (s Server) Drain() {
+	s.inflight.Drain()
+}
+
+// Wait blocks until the server has no requests in flight, or until the context
+// is done, in which case the context's error is returned.
+func (s Server) Wait(ctx context.Context) error {
+	return s.inflight.Wait(ctx)
+}
+
+// InFlight returns the number of requests currently being served.
+func (s Server) InFlight() int64 {
+	return s.inflight.InFlight()
+}
+
+// ErrDraining is passed to the ErrorEncoder for requests received after Drain
+// was called. With the DefaultErrorEncoder, it yields a 503 Service Unavailable
+// response, which asks the client to close the connection.
+var ErrDraining error = drainingError{errors.New("server is draining")}
+
+type drainingError struct{ error }
+
+func (drainingError) StatusCode() int { return http.StatusServiceUnavailable }
+
+func (drainingError) Headers() http.Header { return http.Header{"Connection": []string{"close"}} }
+
 // ErrorEncoder is responsible for encoding an error to the ResponseWriter.
 // Users are encouraged to use custom ErrorEncoders to encode HTTP errors to
 // their clients, and will likely want to pass and check for their own error
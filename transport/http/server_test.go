@@ -347,3 +347,56 @@ func testServer(t *testing.T) (step func(), resp <-chan *http.Response) {
 	}()
 	return func() { stepch <- true }, response
 }
+
+func TestServerDraining(t *testing.T) {
+	var (
+		stepch   = make(chan bool)
+		endpoint = func(context.Context, interface{}) (interface{}, error) { <-stepch; return struct{}{}, nil }
+		handler  = httptransport.NewServer(
+			endpoint,
+			func(context.Context, *http.Request) (interface{}, error) { return struct{}{}, nil },
+			func(context.Context, http.ResponseWriter, interface{}) error { return nil },
+		)
+	)
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	// One request in flight.
+	response := make(chan *http.Response)
+	go func() {
+		resp, err := http.Get(server.URL)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		response <- resp
+	}()
+	for handler.InFlight() != 1 {
+		time.Sleep(time.Millisecond)
+	}
+
+	// New requests are rejected while draining.
+	handler.Drain()
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, have := http.StatusServiceUnavailable, resp.StatusCode; want != have {
+		t.Errorf("want %d, have %d", want, have)
+	}
+
+	// Wait blocks until the in-flight request finishes.
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if want, have := context.DeadlineExceeded, handler.Wait(ctx); want != have {
+		t.Errorf("want %v, have %v", want, have)
+	}
+
+	stepch <- true
+	if want, have := http.StatusOK, (<-response).StatusCode; want != have {
+		t.Errorf("want %d, have %d", want, have)
+	}
+	if err := handler.Wait(context.Background()); err != nil {
+		t.Error(err)
+	}
+}
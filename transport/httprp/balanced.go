@@ -0,0 +1,156 @@
+package httprp
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/guherbozdogan/kit/endpoint"
+	"github.com/guherbozdogan/kit/sd"
+	"github.com/guherbozdogan/kit/sd/lb"
+)
+
+const (
+	// DefaultRetries is the number of times a balanced server retries an
+	// idempotent request on another target after a connection failure.
+	DefaultRetries = 2
+
+	// DefaultMarkDown is how long a balanced server skips a target after a
+	// connection failure.
+	DefaultMarkDown = 10 * time.Second
+)
+
+// NewBalancedServer constructs a new server that proxies each request to a
+// target selected by a balancer over the instances reported by the subscriber.
+// The subscriber's endpoints must yield a *url.URL, like the endpoints made by
+// TargetFactory. If newBalancer is nil, a round robin balancer is used.
+//
+// Targets that fail to connect are marked down and skipped by the balancer for
+// a while; if all targets are down, all of them are tried again. Requests with
+// an idempotent method and no body are retried on another target after such a
+// failure.
+func NewBalancedServer(
+	subscriber sd.Subscriber,
+	newBalancer func(sd.Subscriber) lb.Balancer,
+	options ...ServerOption,
+) *Server {
+	if newBalancer == nil {
+		newBalancer = lb.NewRoundRobin
+	}
+	down := &downSet{until: map[string]time.Time{}}
+	return NewServer(nil, append([]ServerOption{
+		ServerBalancer(newBalancer(upSubscriber{subscriber, down})),
+		func(s *Server) {
+			s.down = down
+			s.retries = DefaultRetries
+			s.markDown = DefaultMarkDown
+		},
+	}, options...)...)
+}
+
+// ServerRetries sets the number of times a balanced server retries an
+// idempotent request after a connection failure. By default, DefaultRetries
+// is used.
+func ServerRetries(n int) ServerOption {
+	return func(s *Server) { s.retries = n }
+}
+
+// ServerMarkDown sets how long a balanced server skips a target after a
+// connection failure. By default, DefaultMarkDown is used.
+func ServerMarkDown(d time.Duration) ServerOption {
+	return func(s *Server) { s.markDown = d }
+}
+
+// attempt tracks a single try to proxy a request.
+type attempt struct {
+	retry  bool  // whether a failed round trip may be retried
+	failed bool  // whether the round trip failed
+	err    error // the error of a failed attempt that is to be retried
+}
+
+// roundTripper reports connection failures to the attempt and the down set.
+type roundTripper struct{ s *Server }
+
+func (t roundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
+	resp, err := t.s.transport.RoundTrip(r)
+	if err == nil {
+		return resp, nil
+	}
+
+	ctx := r.Context()
+	if ctx.Err() != nil {
+		return nil, err // the client went away, the target is fine
+	}
+	if a, ok := ctx.Value(attemptContextKey).(*attempt); ok {
+		a.failed = true
+	}
+	if target, ok := ctx.Value(targetContextKey).(*url.URL); ok && t.s.down != nil {
+		t.s.down.mark(target.String(), time.Now().Add(t.s.markDown))
+	}
+	return nil, err
+}
+
+// retryable reports whether r can safely be sent again.
+func retryable(r *http.Request) bool {
+	switch r.Method {
+	case "GET", "HEAD", "OPTIONS", "TRACE", "PUT", "DELETE":
+		return r.ContentLength == 0
+	}
+	return false
+}
+
+// downSet holds the targets that are marked down, and until when.
+type downSet struct {
+	mtx   sync.Mutex
+	until map[string]time.Time
+}
+
+func (d *downSet) mark(target string, until time.Time) {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	d.until[target] = until
+}
+
+func (d *downSet) isDown(target string, now time.Time) bool {
+	d.mtx.Lock()
+	defer d.mtx.Unlock()
+	until, ok := d.until[target]
+	if ok && !now.Before(until) {
+		delete(d.until, target)
+		return false
+	}
+	return ok
+}
+
+// upSubscriber yields the endpoints whose targets aren't marked down, or all
+// endpoints if every target is down.
+type upSubscriber struct {
+	sd.Subscriber
+	down *downSet
+}
+
+func (s upSubscriber) Endpoints() ([]endpoint.Endpoint, error) {
+	endpoints, err := s.Subscriber.Endpoints()
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		now = time.Now()
+		up  = make([]endpoint.Endpoint, 0, len(endpoints))
+	)
+	for _, e := range endpoints {
+		if target, err := e(context.Background(), nil); err == nil {
+			if u, ok := target.(*url.URL); ok && s.down.isDown(u.String(), now) {
+				continue
+			}
+		}
+		up = append(up, e)
+	}
+	if len(up) == 0 {
+		return endpoints, nil
+	}
+	return up, nil
+}
@@ -0,0 +1,97 @@
+package httprp_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/guherbozdogan/kit/endpoint"
+	"github.com/guherbozdogan/kit/sd"
+	httptransport "github.com/guherbozdogan/kit/transport/httprp"
+)
+
+func TestBalancedServerRetriesAndMarksDown(t *testing.T) {
+	var hits uint64
+	liveServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddUint64(&hits, 1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer liveServer.Close()
+
+	handler := httptransport.NewBalancedServer(
+		sd.FixedSubscriber{deadTarget(t), target(t, liveServer.URL)},
+		nil,
+	)
+	proxyServer := httptest.NewServer(handler)
+	defer proxyServer.Close()
+
+	for i := 0; i < 3; i++ {
+		resp, err := http.Get(proxyServer.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want, have := http.StatusOK, resp.StatusCode; want != have {
+			t.Errorf("want %d, have %d", want, have)
+		}
+	}
+	if want, have := uint64(3), atomic.LoadUint64(&hits); want != have {
+		t.Errorf("want %d, have %d", want, have)
+	}
+}
+
+func TestBalancedServerNoRetryForBody(t *testing.T) {
+	liveServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer liveServer.Close()
+
+	handler := httptransport.NewBalancedServer(
+		sd.FixedSubscriber{deadTarget(t), target(t, liveServer.URL)},
+		nil,
+	)
+	proxyServer := httptest.NewServer(handler)
+	defer proxyServer.Close()
+
+	resp, err := http.Post(proxyServer.URL, "text/plain", strings.NewReader("hey"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, have := http.StatusBadGateway, resp.StatusCode; want != have {
+		t.Errorf("want %d, have %d", want, have)
+	}
+}
+
+func TestBalancedServerAllDown(t *testing.T) {
+	handler := httptransport.NewBalancedServer(
+		sd.FixedSubscriber{deadTarget(t)},
+		nil,
+		httptransport.ServerRetries(1),
+	)
+	proxyServer := httptest.NewServer(handler)
+	defer proxyServer.Close()
+
+	resp, err := http.Get(proxyServer.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, have := http.StatusBadGateway, resp.StatusCode; want != have {
+		t.Errorf("want %d, have %d", want, have)
+	}
+}
+
+func target(t *testing.T, instance string) endpoint.Endpoint {
+	e, _, err := httptransport.TargetFactory("http")(instance)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+// deadTarget returns the target of a server that has been shut down.
+func deadTarget(t *testing.T) endpoint.Endpoint {
+	s := httptest.NewServer(http.NotFoundHandler())
+	s.Close()
+	return target(t, s.URL)
+}
@@ -1,4 +1,5 @@
 // Package httprp provides an HTTP reverse-proxy transport. HTTP handlers that
 // need to proxy requests to another HTTP service can do so with this package by
-// specifying the URL to forward the request to.
+// specifying the URL to forward the request to, or a load balancer to select
+// it from per request.
 package httprp
@@ -0,0 +1,28 @@
+package httprp
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/guherbozdogan/kit/endpoint"
+	"github.com/guherbozdogan/kit/sd"
+)
+
+// TargetFactory returns a factory for ServerBalancer, which turns service
+// discovery instances into endpoints yielding the instance's base URL. Plain
+// host:port instances are given the scheme; instances that are already URLs
+// are used as they are.
+func TargetFactory(scheme string) sd.Factory {
+	return func(instance string) (endpoint.Endpoint, io.Closer, error) {
+		if !strings.Contains(instance, "://") {
+			instance = scheme + "://" + instance
+		}
+		target, err := url.Parse(instance)
+		if err != nil {
+			return nil, nil, err
+		}
+		return func(context.Context, interface{}) (interface{}, error) { return target, nil }, nil, nil
+	}
+}
@@ -2,9 +2,14 @@ package httprp
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
+	"strings"
+	"time"
+
+	"github.com/guherbozdogan/kit/sd/lb"
 )
 
 // RequestFunc may take information from an HTTP request and put it into a
@@ -12,27 +17,63 @@ import (
 // endpoint.
 type RequestFunc func(context.Context, *http.Request) context.Context
 
+// RewriteRequestFunc may modify the outgoing request after its target has been
+// set, e.g. to add headers carried in the request context.
+type RewriteRequestFunc func(context.Context, *http.Request)
+
+// RewriteResponseFunc may modify the response of the target before it's copied
+// to the client. If it returns an error, the error encoder is invoked instead.
+type RewriteResponseFunc func(context.Context, *http.Response) error
+
+// ErrorEncoder is responsible for encoding an error to the ResponseWriter,
+// when the target can't be selected or reached, or a RewriteResponseFunc
+// fails.
+type ErrorEncoder func(ctx context.Context, err error, w http.ResponseWriter)
+
 // Server is a proxying request handler.
 type Server struct {
-	proxy        http.Handler
-	before       []RequestFunc
-	errorEncoder func(w http.ResponseWriter, err error)
+	proxy           *httputil.ReverseProxy
+	target          *url.URL
+	balancer        lb.Balancer
+	before          []RequestFunc
+	rewriteRequest  []RewriteRequestFunc
+	rewriteResponse []RewriteResponseFunc
+	errorEncoder    ErrorEncoder
+	transport       http.RoundTripper
+	retries         int
+	markDown        time.Duration
+	down            *downSet
 }
 
 // NewServer constructs a new server that implements http.Server and will proxy
 // requests to the given base URL using its scheme, host, and base path.
 // If the target's path is "/base" and the incoming request was for "/dir",
-// the target request will be for /base/dir.
+// the target request will be for /base/dir. If the ServerBalancer option is
+// given, the base URL is selected per request instead, and may be nil.
 func NewServer(
 	baseURL *url.URL,
 	options ...ServerOption,
 ) *Server {
 	s := &Server{
-		proxy: httputil.NewSingleHostReverseProxy(baseURL),
+		target:       baseURL,
+		errorEncoder: DefaultErrorEncoder,
+		transport:    http.DefaultTransport,
 	}
 	for _, option := range options {
 		option(s)
 	}
+	s.proxy = &httputil.ReverseProxy{
+		Director:       s.direct,
+		Transport:      roundTripper{s},
+		ModifyResponse: s.modifyResponse,
+		ErrorHandler: func(w http.ResponseWriter, r *http.Request, err error) {
+			if a, ok := r.Context().Value(attemptContextKey).(*attempt); ok && a.retry && a.failed {
+				a.err = err // leave the response to the next attempt
+				return
+			}
+			s.errorEncoder(r.Context(), err, w)
+		},
+	}
 	return s
 }
 
@@ -45,6 +86,37 @@ func ServerBefore(before ...RequestFunc) ServerOption {
 	return func(s *Server) { s.before = append(s.before, before...) }
 }
 
+// ServerRewriteRequest functions are executed on the outgoing request, after
+// its target has been set.
+func ServerRewriteRequest(f ...RewriteRequestFunc) ServerOption {
+	return func(s *Server) { s.rewriteRequest = append(s.rewriteRequest, f...) }
+}
+
+// ServerRewriteResponse functions are executed on the response of the target,
+// before it's copied to the client.
+func ServerRewriteResponse(f ...RewriteResponseFunc) ServerOption {
+	return func(s *Server) { s.rewriteResponse = append(s.rewriteResponse, f...) }
+}
+
+// ServerErrorEncoder is used to encode errors to the client. By default,
+// DefaultErrorEncoder is used.
+func ServerErrorEncoder(ee ErrorEncoder) ServerOption {
+	return func(s *Server) { s.errorEncoder = ee }
+}
+
+// ServerTransport sets the round tripper used to reach the target. By default,
+// http.DefaultTransport is used.
+func ServerTransport(t http.RoundTripper) ServerOption {
+	return func(s *Server) { s.transport = t }
+}
+
+// ServerBalancer selects the base URL per request from the balancer, instead
+// of using the one given to NewServer. The balancer's endpoints must yield a
+// *url.URL, like the endpoints made by TargetFactory.
+func ServerBalancer(b lb.Balancer) ServerOption {
+	return func(s *Server) { s.balancer = b }
+}
+
 // ServeHTTP implements http.Handler.
 func (s Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -53,5 +125,106 @@ func (s Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		ctx = f(ctx, r)
 	}
 
-	s.proxy.ServeHTTP(w, r)
+	var retries int
+	if s.down != nil && retryable(r) {
+		retries = s.retries
+	}
+
+	for i := 0; ; i++ {
+		target, err := s.selectTarget(ctx)
+		if err != nil {
+			s.errorEncoder(ctx, err, w)
+			return
+		}
+
+		a := &attempt{retry: i < retries}
+		actx := context.WithValue(ctx, targetContextKey, target)
+		actx = context.WithValue(actx, attemptContextKey, a)
+
+		s.proxy.ServeHTTP(w, r.WithContext(actx))
+		if a.err == nil {
+			return
+		}
+	}
+}
+
+func (s Server) selectTarget(ctx context.Context) (*url.URL, error) {
+	if s.balancer == nil {
+		return s.target, nil
+	}
+	e, err := s.balancer.Endpoint()
+	if err != nil {
+		return nil, err
+	}
+	response, err := e(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	target, ok := response.(*url.URL)
+	if !ok {
+		return nil, fmt.Errorf("balancer endpoint yielded %T, want *url.URL", response)
+	}
+	return target, nil
+}
+
+// direct sets the target of the outgoing request like the director of
+// httputil.NewSingleHostReverseProxy, and applies the rewrite funcs.
+func (s Server) direct(r *http.Request) {
+	ctx := r.Context()
+	target := ctx.Value(targetContextKey).(*url.URL)
+
+	r.URL.Scheme = target.Scheme
+	r.URL.Host = target.Host
+	r.URL.Path = singleJoiningSlash(target.Path, r.URL.Path)
+	if target.RawQuery == "" || r.URL.RawQuery == "" {
+		r.URL.RawQuery = target.RawQuery + r.URL.RawQuery
+	} else {
+		r.URL.RawQuery = target.RawQuery + "&" + r.URL.RawQuery
+	}
+	if _, ok := r.Header["User-Agent"]; !ok {
+		// explicitly disable User-Agent so it's not set to default value
+		r.Header.Set("User-Agent", "")
+	}
+
+	for _, f := range s.rewriteRequest {
+		f(ctx, r)
+	}
+}
+
+func (s Server) modifyResponse(resp *http.Response) error {
+	for _, f := range s.rewriteResponse {
+		if err := f(resp.Request.Context(), resp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DefaultErrorEncoder writes 503 Service Unavailable if the balancer has no
+// endpoints, and 502 Bad Gateway for all other errors.
+func DefaultErrorEncoder(_ context.Context, err error, w http.ResponseWriter) {
+	code := http.StatusBadGateway
+	if err == lb.ErrNoEndpoints {
+		code = http.StatusServiceUnavailable
+	}
+	w.WriteHeader(code)
+}
+
+type contextKey int
+
+const (
+	targetContextKey contextKey = iota
+	attemptContextKey
+)
+
+func singleJoiningSlash(a, b string) string {
+	aslash := strings.HasSuffix(a, "/")
+	bslash := strings.HasPrefix(b, "/")
+	switch {
+	case aslash && bslash:
+		return a + b[1:]
+	case !aslash && !bslash:
+		return a + "/" + b
+	}
+	return a + b
 }
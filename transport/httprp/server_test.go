@@ -2,12 +2,15 @@ package httprp_test
 
 import (
 	"context"
+	"errors"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"testing"
 
+	"github.com/guherbozdogan/kit/sd"
+	"github.com/guherbozdogan/kit/sd/lb"
 	httptransport "github.com/guherbozdogan/kit/transport/httprp"
 )
 
@@ -156,3 +159,135 @@ func TestMultipleServerBefore(t *testing.T) {
 		t.Errorf("want %q, have %q", want, have)
 	}
 }
+
+func TestServerContextPropagation(t *testing.T) {
+	type ctxKey struct{}
+	const (
+		headerKey = "X-Trace-Id"
+		headerVal = "abc"
+	)
+
+	originServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if want, have := headerVal, r.Header.Get(headerKey); want != have {
+			t.Errorf("want %q, have %q", want, have)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer originServer.Close()
+	originURL, _ := url.Parse(originServer.URL)
+
+	handler := httptransport.NewServer(
+		originURL,
+		httptransport.ServerBefore(func(ctx context.Context, r *http.Request) context.Context {
+			return context.WithValue(ctx, ctxKey{}, headerVal)
+		}),
+		httptransport.ServerRewriteRequest(func(ctx context.Context, r *http.Request) {
+			if v, ok := ctx.Value(ctxKey{}).(string); ok {
+				r.Header.Set(headerKey, v)
+			}
+		}),
+	)
+	proxyServer := httptest.NewServer(handler)
+	defer proxyServer.Close()
+
+	resp, _ := http.Get(proxyServer.URL)
+	if want, have := http.StatusOK, resp.StatusCode; want != have {
+		t.Errorf("want %d, have %d", want, have)
+	}
+}
+
+func TestServerRewriteResponse(t *testing.T) {
+	originServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Internal", "secret")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer originServer.Close()
+	originURL, _ := url.Parse(originServer.URL)
+
+	handler := httptransport.NewServer(
+		originURL,
+		httptransport.ServerRewriteResponse(func(ctx context.Context, resp *http.Response) error {
+			resp.Header.Del("X-Internal")
+			return nil
+		}),
+	)
+	proxyServer := httptest.NewServer(handler)
+	defer proxyServer.Close()
+
+	resp, _ := http.Get(proxyServer.URL)
+	if want, have := "", resp.Header.Get("X-Internal"); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+}
+
+func TestServerErrorEncoder(t *testing.T) {
+	errRejected := errors.New("rejected")
+
+	originServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer originServer.Close()
+	originURL, _ := url.Parse(originServer.URL)
+
+	var encoded error
+	handler := httptransport.NewServer(
+		originURL,
+		httptransport.ServerRewriteResponse(func(ctx context.Context, resp *http.Response) error {
+			return errRejected
+		}),
+		httptransport.ServerErrorEncoder(func(ctx context.Context, err error, w http.ResponseWriter) {
+			encoded = err
+			w.WriteHeader(http.StatusTeapot)
+		}),
+	)
+	proxyServer := httptest.NewServer(handler)
+	defer proxyServer.Close()
+
+	resp, _ := http.Get(proxyServer.URL)
+	if want, have := http.StatusTeapot, resp.StatusCode; want != have {
+		t.Errorf("want %d, have %d", want, have)
+	}
+	if want, have := errRejected, encoded; want != have {
+		t.Errorf("want %v, have %v", want, have)
+	}
+}
+
+func TestServerBalancer(t *testing.T) {
+	originServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.URL.Path))
+	}))
+	defer originServer.Close()
+	originURL, _ := url.Parse(originServer.URL)
+
+	e, _, err := httptransport.TargetFactory("http")(originURL.Host + "/base")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := httptransport.NewServer(
+		nil,
+		httptransport.ServerBalancer(lb.NewRoundRobin(sd.FixedSubscriber{e})),
+	)
+	proxyServer := httptest.NewServer(handler)
+	defer proxyServer.Close()
+
+	resp, _ := http.Get(proxyServer.URL + "/dir")
+	responseBody, _ := ioutil.ReadAll(resp.Body)
+	if want, have := "/base/dir", string(responseBody); want != have {
+		t.Errorf("want %q, have %q", want, have)
+	}
+}
+
+func TestServerBalancerNoEndpoints(t *testing.T) {
+	handler := httptransport.NewServer(
+		nil,
+		httptransport.ServerBalancer(lb.NewRoundRobin(sd.FixedSubscriber{})),
+	)
+	proxyServer := httptest.NewServer(handler)
+	defer proxyServer.Close()
+
+	resp, _ := http.Get(proxyServer.URL)
+	if want, have := http.StatusServiceUnavailable, resp.StatusCode; want != have {
+		t.Errorf("want %d, have %d", want, have)
+	}
+}
@@ -0,0 +1,88 @@
+// Package inflight implements a goroutine-safe counter of in-flight requests,
+// with support for draining. It's shared by the server implementations in
+// package transport, so that they can stop accepting new requests and wait for
+// outstanding ones to finish before shutting down.
+package inflight
+
+import (
+	"context"
+	"sync"
+)
+
+// Tracker counts in-flight requests. The zero value is not usable; use New.
+type Tracker struct {
+	mtx      sync.Mutex
+	n        int64
+	draining bool
+	idle     chan struct{} // closed whenever n == 0
+}
+
+// New returns a new Tracker without any in-flight requests.
+func New() *Tracker {
+	idle := make(chan struct{})
+	close(idle)
+	return &Tracker{idle: idle}
+}
+
+// Begin records the start of a request. It returns false if the tracker is
+// draining, in which case the request should be rejected, and End must not be
+// called.
+func (t *Tracker) Begin() bool {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	if t.draining {
+		return false
+	}
+	if t.n == 0 {
+		t.idle = make(chan struct{})
+	}
+	t.n++
+	return true
+}
+
+// End records the end of a request started with a successful Begin.
+func (t *Tracker) End() {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	t.n--
+	if t.n == 0 {
+		close(t.idle)
+	}
+}
+
+// Drain makes subsequent calls to Begin fail.
+func (t *Tracker) Drain() {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	t.draining = true
+}
+
+// Draining reports whether Drain was called.
+func (t *Tracker) Draining() bool {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	return t.draining
+}
+
+// InFlight returns the number of in-flight requests.
+func (t *Tracker) InFlight() int64 {
+	t.mtx.Lock()
+	defer t.mtx.Unlock()
+	return t.n
+}
+
+// Wait blocks until there are no in-flight requests, or until the context is
+// done, in which case the context's error is returned. Wait doesn't prevent
+// new requests from starting; call Drain first for that.
+func (t *Tracker) Wait(ctx context.Context) error {
+	t.mtx.Lock()
+	idle := t.idle
+	t.mtx.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
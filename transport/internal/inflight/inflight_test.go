@@ -0,0 +1,53 @@
+package inflight
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTracker(t *testing.T) {
+	tr := New()
+
+	if err := tr.Wait(context.Background()); err != nil {
+		t.Fatalf("idle tracker: %v", err)
+	}
+
+	if !tr.Begin() || !tr.Begin() {
+		t.Fatal("Begin failed before Drain")
+	}
+	if want, have := int64(2), tr.InFlight(); want != have {
+		t.Errorf("want %d, have %d", want, have)
+	}
+
+	tr.Drain()
+	if tr.Begin() {
+		t.Error("Begin succeeded after Drain")
+	}
+	if !tr.Draining() {
+		t.Error("want draining")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if want, have := context.DeadlineExceeded, tr.Wait(ctx); want != have {
+		t.Errorf("want %v, have %v", want, have)
+	}
+
+	donec := make(chan error)
+	go func() { donec <- tr.Wait(context.Background()) }()
+	tr.End()
+	tr.End()
+
+	select {
+	case err := <-donec:
+		if err != nil {
+			t.Error(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Wait didn't return after all requests ended")
+	}
+	if want, have := int64(0), tr.InFlight(); want != have {
+		t.Errorf("want %d, have %d", want, have)
+	}
+}